@@ -9,6 +9,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/leonhfr/mochi/internal/action"
+	"github.com/leonhfr/mochi/internal/report"
 )
 
 // GetApp returns the cli app.
@@ -52,6 +53,82 @@ func GetApp(out io.Writer, version, compiled string) (*cli.App, error) {
 					},
 				},
 			},
+			{
+				Name:  "pull",
+				Usage: "exports the decks into markdown files",
+				Action: func(ctx *cli.Context) error {
+					pwd, err := os.Getwd()
+					if err != nil {
+						return err
+					}
+
+					token := ctx.String("token")
+					workspace := ctx.Args().First()
+					workspace = filepath.Join(pwd, workspace)
+
+					return action.Pull(ctx.Context, logger, token, workspace)
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "token",
+						Aliases: []string{"t"},
+						Usage:   "mochi API token",
+						EnvVars: []string{"MOCHI_API_TOKEN"},
+					},
+				},
+			},
+			{
+				Name:  "relink",
+				Usage: "rebuilds the lockfile from the remote decks and cards",
+				Action: func(ctx *cli.Context) error {
+					pwd, err := os.Getwd()
+					if err != nil {
+						return err
+					}
+
+					token := ctx.String("token")
+					workspace := ctx.Args().First()
+					workspace = filepath.Join(pwd, workspace)
+
+					return action.Relink(ctx.Context, logger, token, workspace)
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "token",
+						Aliases: []string{"t"},
+						Usage:   "mochi API token",
+						EnvVars: []string{"MOCHI_API_TOKEN"},
+					},
+				},
+			},
+			{
+				Name:  "plan",
+				Usage: "prints the sync requests without executing them",
+				Action: func(ctx *cli.Context) error {
+					pwd, err := os.Getwd()
+					if err != nil {
+						return err
+					}
+
+					token := ctx.String("token")
+					workspace := ctx.Args().First()
+					workspace = filepath.Join(pwd, workspace)
+
+					return action.Plan(ctx.Context, logger, token, workspace, ctx.Bool("full"))
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "token",
+						Aliases: []string{"t"},
+						Usage:   "mochi API token",
+						EnvVars: []string{"MOCHI_API_TOKEN"},
+					},
+					&cli.BoolFlag{
+						Name:  "full",
+						Usage: "sync every directory, even the unchanged ones",
+					},
+				},
+			},
 		},
 		Action: func(ctx *cli.Context) error {
 			pwd, err := os.Getwd()
@@ -63,7 +140,15 @@ func GetApp(out io.Writer, version, compiled string) (*cli.App, error) {
 			workspace := ctx.Args().First()
 			workspace = filepath.Join(pwd, workspace)
 
-			_, err = action.Sync(ctx.Context, logger, token, workspace)
+			rep, err := action.Sync(ctx.Context, logger, token, workspace, action.SyncOptions{
+				Force: ctx.Bool("force"),
+				Full:  ctx.Bool("full"),
+			})
+			if path := ctx.String("report"); path != "" {
+				if writeErr := writeReport(path, rep); err == nil {
+					err = writeErr
+				}
+			}
 			return err
 		},
 		Flags: []cli.Flag{
@@ -73,6 +158,30 @@ func GetApp(out io.Writer, version, compiled string) (*cli.App, error) {
 				Usage:   "mochi API token",
 				EnvVars: []string{"MOCHI_API_TOKEN"},
 			},
+			&cli.StringFlag{
+				Name:    "report",
+				Aliases: []string{"r"},
+				Usage:   "write a JSON sync report to `FILE`",
+			},
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "execute the requests even above the deletion threshold",
+			},
+			&cli.BoolFlag{
+				Name:  "full",
+				Usage: "sync every directory, even the unchanged ones",
+			},
 		},
 	}, nil
 }
+
+func writeReport(path string, rep *report.Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return rep.Write(file)
+}
@@ -3,6 +3,7 @@ package github
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 
 	"github.com/sethvargo/go-githubactions"
 )
@@ -10,21 +11,69 @@ import (
 const (
 	apiTokenInput  = "api_token"
 	workspaceInput = "workspace"
+	dryRunInput    = "dry_run"
+	forceInput     = "force"
+	fullInput      = "full"
 )
 
+// Input holds the action inputs.
+type Input struct {
+	Token     string
+	Workspace string
+	DryRun    bool
+	Force     bool
+	Full      bool
+}
+
 // GetInput returns the action inputs.
-func GetInput(gha *githubactions.Action) (string, string, error) {
+func GetInput(gha *githubactions.Action) (Input, error) {
 	ghc, err := gha.Context()
 	if err != nil {
-		return "", "", err
+		return Input{}, err
 	}
 
 	token := gha.GetInput(apiTokenInput)
 	if token == "" {
-		return "", "", fmt.Errorf("%s required", apiTokenInput)
+		return Input{}, fmt.Errorf("%s required", apiTokenInput)
 	}
 
 	workspace := gha.GetInput(workspaceInput)
 	workspace = filepath.Join(ghc.Workspace, workspace)
-	return token, workspace, nil
+
+	dryRun, err := getBoolInput(gha, dryRunInput)
+	if err != nil {
+		return Input{}, err
+	}
+
+	force, err := getBoolInput(gha, forceInput)
+	if err != nil {
+		return Input{}, err
+	}
+
+	full, err := getBoolInput(gha, fullInput)
+	if err != nil {
+		return Input{}, err
+	}
+
+	return Input{
+		Token:     token,
+		Workspace: workspace,
+		DryRun:    dryRun,
+		Force:     force,
+		Full:      full,
+	}, nil
+}
+
+func getBoolInput(gha *githubactions.Action, name string) (bool, error) {
+	input := gha.GetInput(name)
+	if input == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(input)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return value, nil
 }
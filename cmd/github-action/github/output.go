@@ -4,11 +4,24 @@ import (
 	"fmt"
 
 	"github.com/sethvargo/go-githubactions"
+
+	"github.com/leonhfr/mochi/internal/report"
 )
 
-const lockfileUpdatedOutput = "lockfile_updated"
+const (
+	lockfileUpdatedOutput = "lockfile_updated"
+	reportOutput          = "report"
+)
 
 // SetOutput sets the action output.
-func SetOutput(gha *githubactions.Action, updated bool) {
-	gha.SetOutput(lockfileUpdatedOutput, fmt.Sprintf("%t", updated))
+func SetOutput(gha *githubactions.Action, rep *report.Report) error {
+	gha.SetOutput(lockfileUpdatedOutput, fmt.Sprintf("%t", rep.LockfileUpdated))
+
+	bytes, err := rep.JSON()
+	if err != nil {
+		return err
+	}
+
+	gha.SetOutput(reportOutput, string(bytes))
+	return nil
 }
@@ -22,12 +22,21 @@ func run(ctx context.Context, gha *githubactions.Action) error {
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, os.Kill)
 	defer stop()
 
-	token, workspace, err := github.GetInput(gha)
+	input, err := github.GetInput(gha)
 	if err != nil {
 		return err
 	}
 
-	updated, err := action.Sync(ctx, gha, token, workspace)
-	github.SetOutput(gha, updated)
+	if input.DryRun {
+		return action.Plan(ctx, gha, input.Token, input.Workspace, input.Full)
+	}
+
+	rep, err := action.Sync(ctx, gha, input.Token, input.Workspace, action.SyncOptions{
+		Force: input.Force,
+		Full:  input.Full,
+	})
+	if outputErr := github.SetOutput(gha, rep); err == nil {
+		err = outputErr
+	}
 	return err
 }
@@ -5,8 +5,9 @@ import (
 	"sync"
 
 	"github.com/leonhfr/mochi/internal/file"
+	"github.com/leonhfr/mochi/internal/lock"
 	"github.com/leonhfr/mochi/internal/parser"
-	"github.com/leonhfr/mochi/internal/request"
+	"github.com/leonhfr/mochi/internal/report"
 	"github.com/leonhfr/mochi/internal/worker"
 )
 
@@ -41,7 +42,7 @@ func Dump(ctx context.Context, logger Logger, token, workspace string) (err erro
 
 	dumpR := worker.DumpRequests(ctx, logger, client, deckC)
 	dumpC := worker.Unwrap(wg, dumpR, errC)
-	doneR := worker.ExecuteRequests(ctx, logger, client, lf, dumpC)
+	doneR := worker.ExecuteRequests(ctx, logger, client, lf, report.New(), dumpC)
 	_ = worker.Unwrap(wg, doneR, errC)
 
 	wg.Wait()
@@ -60,13 +61,17 @@ func Dump(ctx context.Context, logger Logger, token, workspace string) (err erro
 	return err
 }
 
-var _ request.Lockfile = &noOpLockfile{}
+var _ worker.RequestLockfile = &noOpLockfile{}
 
 type noOpLockfile struct{}
 
 func (lf *noOpLockfile) Lock()   {}
 func (lf *noOpLockfile) Unlock() {}
 
-func (lf *noOpLockfile) SetCard(_, _, _ string) error {
+func (lf *noOpLockfile) SetCard(_, _ string, _ lock.Card) error {
 	return nil
 }
+
+func (lf *noOpLockfile) DeleteCard(_, _ string) {}
+
+func (lf *noOpLockfile) ResetFiles(_ string) {}
@@ -0,0 +1,36 @@
+package action
+
+import (
+	"context"
+
+	"github.com/leonhfr/mochi/internal/file"
+	"github.com/leonhfr/mochi/internal/parser"
+	"github.com/leonhfr/mochi/syncer"
+)
+
+// Plan prints the requests a sync would execute, grouped by deck.
+//
+// Neither the requests nor the deck creations are executed
+// and the lockfile is not written. Unless full is set, unchanged
+// directories are skipped like during a sync.
+func Plan(ctx context.Context, logger Logger, token, workspace string, full bool) error {
+	logger.Infof("workspace: %s", workspace)
+
+	fs := file.NewSystem()
+	config, err := loadConfig(fs, logger, parser.Names(), workspace)
+	if err != nil {
+		return err
+	}
+
+	client := loadClient(logger, config.RateLimit, token)
+
+	_, err = syncer.New(
+		fs,
+		client,
+		syncer.WithConfig(config),
+		syncer.WithLogger(logger),
+		syncer.WithFull(full),
+		syncer.WithDryRun(true),
+	).Sync(ctx, workspace)
+	return err
+}
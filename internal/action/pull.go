@@ -0,0 +1,45 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/leonhfr/mochi/internal/config"
+	"github.com/leonhfr/mochi/internal/file"
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/internal/parser"
+	"github.com/leonhfr/mochi/internal/pull"
+)
+
+// Pull exports the mochi decks into the workspace, along with
+// a matching config and lockfile.
+//
+// The workspace must not already contain a config.
+func Pull(ctx context.Context, logger Logger, token, workspace string) error {
+	logger.Infof("workspace: %s", workspace)
+
+	fs := file.NewSystem()
+	if _, err := config.Parse(fs, workspace, parser.Names()); !errors.Is(err, config.ErrNoConfig) {
+		return fmt.Errorf("workspace %s already contains a config", workspace)
+	}
+
+	client := loadClient(logger, config.DefaultRateLimit, token)
+
+	lf, err := lock.Parse(fs, workspace)
+	if err != nil {
+		return err
+	}
+
+	result, err := pull.Pull(ctx, logger, client, fs, lf, workspace)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("pull: %d decks, %d cards and %d attachments exported", result.Decks, result.Cards, result.Attachments)
+	if result.Skipped > 0 {
+		logger.Infof("pull: %d archived or templated cards skipped, deletion policy set to keep", result.Skipped)
+	}
+
+	return lf.Write()
+}
@@ -0,0 +1,74 @@
+package action
+
+import (
+	"context"
+
+	"github.com/leonhfr/mochi/internal/card"
+	"github.com/leonhfr/mochi/internal/converter"
+	"github.com/leonhfr/mochi/internal/file"
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/internal/parser"
+	"github.com/leonhfr/mochi/internal/relink"
+	"github.com/leonhfr/mochi/internal/worker"
+)
+
+// Relink rebuilds the lockfile by matching the workspace
+// with the remote decks and cards.
+//
+// The existing lockfile is ignored and overwritten.
+func Relink(ctx context.Context, logger Logger, token, workspace string) error {
+	logger.Infof("workspace: %s", workspace)
+
+	fs := file.NewSystem()
+	config, err := loadConfig(fs, logger, parser.Names(), workspace)
+	if err != nil {
+		return err
+	}
+
+	parser, err := parser.New(parser.ConfigOptions(config, workspace)...)
+	if err != nil {
+		return err
+	}
+	converter := converter.New()
+
+	client := loadClient(logger, config.RateLimit, token)
+
+	decks, err := client.ListDecks(ctx)
+	if err != nil {
+		return err
+	}
+
+	dirC, err := worker.FileWalk(ctx, logger, fs, workspace, parser.Extensions(), worker.IgnoredFiles(), nil)
+	if err != nil {
+		return err
+	}
+
+	var dirs []relink.Directory
+	for group := range dirC {
+		deckConfig, ok := config.Deck(group.Base)
+		if !ok {
+			logger.Infof("relink(%s): discarding directory", group.Base)
+			continue
+		}
+
+		cards, err := card.Parse(fs, parser, converter, workspace, deckConfig.Parser, worker.DeckPaths(deckConfig, group.Items))
+		if err != nil {
+			return err
+		}
+
+		dirs = append(dirs, relink.Directory{Path: group.Base, Cards: cards})
+	}
+
+	lf := lock.New(fs, workspace)
+	result, err := relink.Relink(ctx, logger, client, config, lf, decks, dirs)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("relink: %d decks and %d cards matched", result.Decks, result.Cards)
+	for _, ambiguity := range result.Ambiguities {
+		logger.Errorf("relink: ambiguous match, skipped: %s", ambiguity)
+	}
+
+	return lf.Write()
+}
@@ -2,7 +2,9 @@ package card
 
 import (
 	"io"
+	"maps"
 	"path/filepath"
+	"slices"
 
 	"github.com/leonhfr/mochi/internal/converter"
 	"github.com/leonhfr/mochi/internal/heap"
@@ -22,11 +24,29 @@ type Parser interface {
 // Converter represents the interface to convert cards.
 type Converter interface {
 	Convert(reader converter.Reader, path string, source string) (converter.Result, error)
+	ConvertAudio(reader converter.Reader, path, name string) (converter.Result, bool)
 }
 
 // Parse parses the note files for cards.
 func Parse(r Reader, p Parser, c Converter, workspace, parserName string, filePaths []string) ([]Card, error) {
-	var cards []Card
+	files, err := ParseFiles(r, p, workspace, parserName, filePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	return Convert(r, c, files)
+}
+
+// File contains the cards parsed from a note file, before their conversion.
+type File struct {
+	Path  string // absolute path of the note file
+	Deck  string
+	Cards []parser.Card
+}
+
+// ParseFiles parses the note files for cards, without converting them.
+func ParseFiles(r Reader, p Parser, workspace, parserName string, filePaths []string) ([]File, error) {
+	files := make([]File, 0, len(filePaths))
 	for _, path := range filePaths {
 		path = filepath.Join(workspace, path)
 
@@ -35,7 +55,16 @@ func Parse(r Reader, p Parser, c Converter, workspace, parserName string, filePa
 			return nil, err
 		}
 
-		converted, err := convertCards(r, c, deck, path, parsedCards)
+		files = append(files, File{Path: path, Deck: deck, Cards: parsedCards})
+	}
+	return files, nil
+}
+
+// Convert converts the cards parsed from the note files.
+func Convert(r Reader, c Converter, files []File) ([]Card, error) {
+	var cards []Card
+	for _, file := range files {
+		converted, err := convertCards(r, c, file.Deck, file.Path, file.Cards)
 		if err != nil {
 			return nil, err
 		}
@@ -61,11 +90,41 @@ func convertCards(r Reader, c Converter, deck, path string, parsedCards []parser
 		if err != nil {
 			return nil, err
 		}
-		cards = append(cards, newCard(deck, converted.Markdown, card, converted.Attachments))
+
+		attachments := converted.Attachments
+		card.Fields, attachments = convertAudio(r, c, path, card, attachments)
+		cards = append(cards, newCard(deck, converted.Markdown, card, attachments, converted.Pending))
 	}
 	return cards, nil
 }
 
+// convertAudio fills the audio fields of the card with the audio files found.
+//
+// The fields whose audio file is not found are left empty.
+func convertAudio(r Reader, c Converter, path string, card parser.Card, attachments []converter.Attachment) (map[string]string, []converter.Attachment) {
+	if len(card.Audio) == 0 {
+		return card.Fields, attachments
+	}
+
+	fields := maps.Clone(card.Fields)
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	for _, field := range slices.Sorted(maps.Keys(card.Audio)) {
+		converted, ok := c.ConvertAudio(r, path, card.Audio[field])
+		if !ok {
+			continue
+		}
+		fields[field] = converted.Markdown
+		for _, attachment := range converted.Attachments {
+			if !slices.ContainsFunc(attachments, func(a converter.Attachment) bool { return a.Filename == attachment.Filename }) {
+				attachments = append(attachments, attachment)
+			}
+		}
+	}
+	return fields, attachments
+}
+
 // Heap creates a card heap from cards.
 func Heap(cards []Card) *heap.Heap[Card] {
 	h := heap.New[Card]()
@@ -79,16 +138,18 @@ func Heap(cards []Card) *heap.Heap[Card] {
 type Card struct {
 	base        string
 	Attachments []converter.Attachment
+	Pending     bool // links to cards not synced yet
 	parser.Card
 }
 
 var _ heap.Item = &Card{}
 
-func newCard(deck, markdown string, card parser.Card, attachments []converter.Attachment) Card {
+func newCard(deck, markdown string, card parser.Card, attachments []converter.Attachment, pending bool) Card {
 	card.Content = markdown
 	return Card{
 		base:        deck,
 		Attachments: attachments,
+		Pending:     pending,
 		Card:        card,
 	}
 }
@@ -82,3 +82,88 @@ func Test_parseFile(t *testing.T) {
 		})
 	}
 }
+
+func Test_convertAudio(t *testing.T) {
+	tests := []struct {
+		name            string
+		calls           []test.ConverterAudioCall
+		card            parser.Card
+		attachments     []converter.Attachment
+		wantFields      map[string]string
+		wantAttachments []converter.Attachment
+	}{
+		{
+			name:            "no audio",
+			card:            parser.Card{Fields: map[string]string{"name": "Hund"}},
+			attachments:     []converter.Attachment{{Filename: "image.png"}},
+			wantFields:      map[string]string{"name": "Hund"},
+			wantAttachments: []converter.Attachment{{Filename: "image.png"}},
+		},
+		{
+			name: "audio found",
+			calls: []test.ConverterAudioCall{{
+				Path: "/testdata/de/vocabulary.md",
+				Name: "Hund",
+				Result: converter.Result{
+					Markdown:    "![](@media/hund.mp3)",
+					Attachments: []converter.Attachment{{Filename: "hund.mp3"}},
+				},
+				Ok: true,
+			}},
+			card: parser.Card{
+				Fields: map[string]string{"name": "Hund"},
+				Audio:  map[string]string{"PRONUNCIATION_ID": "Hund"},
+			},
+			attachments: []converter.Attachment{{Filename: "image.png"}},
+			wantFields: map[string]string{
+				"name":             "Hund",
+				"PRONUNCIATION_ID": "![](@media/hund.mp3)",
+			},
+			wantAttachments: []converter.Attachment{{Filename: "image.png"}, {Filename: "hund.mp3"}},
+		},
+		{
+			name: "audio already attached",
+			calls: []test.ConverterAudioCall{{
+				Path: "/testdata/de/vocabulary.md",
+				Name: "Hund",
+				Result: converter.Result{
+					Markdown:    "![](@media/hund.mp3)",
+					Attachments: []converter.Attachment{{Filename: "hund.mp3"}},
+				},
+				Ok: true,
+			}},
+			card: parser.Card{
+				Fields: map[string]string{"name": "Hund"},
+				Audio:  map[string]string{"PRONUNCIATION_ID": "Hund"},
+			},
+			attachments: []converter.Attachment{{Filename: "hund.mp3"}},
+			wantFields: map[string]string{
+				"name":             "Hund",
+				"PRONUNCIATION_ID": "![](@media/hund.mp3)",
+			},
+			wantAttachments: []converter.Attachment{{Filename: "hund.mp3"}},
+		},
+		{
+			name: "audio not found",
+			calls: []test.ConverterAudioCall{{
+				Path: "/testdata/de/vocabulary.md",
+				Name: "Katze",
+			}},
+			card: parser.Card{
+				Fields: map[string]string{"name": "Katze"},
+				Audio:  map[string]string{"PRONUNCIATION_ID": "Katze"},
+			},
+			wantFields: map[string]string{"name": "Katze"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := test.NewMockConverter(nil).WithAudio(tt.calls)
+			gotFields, gotAttachments := convertAudio(nil, c, "/testdata/de/vocabulary.md", tt.card, tt.attachments)
+			assert.Equal(t, tt.wantFields, gotFields)
+			assert.Equal(t, tt.wantAttachments, gotAttachments)
+			c.AssertExpectations(t)
+		})
+	}
+}
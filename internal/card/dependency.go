@@ -0,0 +1,98 @@
+package card
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// Recorder is a reader recording the hashes of the files read through it,
+// such as the images, audio files and notes embedded in the converted cards.
+//
+// The files that cannot be read are recorded with an empty hash,
+// so that adding them later is detected.
+type Recorder struct {
+	reader    Reader
+	workspace string
+	mu        sync.Mutex
+	hashes    map[string]string // indexed by path relative to the workspace
+}
+
+// NewRecorder returns a new Recorder reading from r.
+func NewRecorder(r Reader, workspace string) *Recorder {
+	return &Recorder{
+		reader:    r,
+		workspace: workspace,
+		hashes:    make(map[string]string),
+	}
+}
+
+// Read implements the Reader interface.
+func (r *Recorder) Read(path string) (io.ReadCloser, error) {
+	content, err := readAll(r.reader, path)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rel := relativePath(r.workspace, path)
+	if err != nil {
+		r.hashes[rel] = ""
+		return nil, err
+	}
+
+	r.hashes[rel] = hashContent(content)
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// Hashes returns the hashes of the files read, except the excluded
+// paths relative to the workspace.
+func (r *Recorder) Hashes(excluded []string) map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hashes := maps.Clone(r.hashes)
+	maps.DeleteFunc(hashes, func(path, _ string) bool {
+		return slices.Contains(excluded, path)
+	})
+	return hashes
+}
+
+// HashDependencies returns the hashes of the files at the paths
+// relative to the workspace, empty for the files that cannot be read.
+func HashDependencies(r Reader, workspace string, paths []string) map[string]string {
+	hashes := make(map[string]string, len(paths))
+	for _, path := range paths {
+		if content, err := readAll(r, filepath.Join(workspace, path)); err == nil {
+			hashes[path] = hashContent(content)
+		} else {
+			hashes[path] = ""
+		}
+	}
+	return hashes
+}
+
+func readAll(r Reader, path string) ([]byte, error) {
+	rc, err := r.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func relativePath(workspace, path string) string {
+	rel, err := filepath.Rel(workspace, path)
+	if err != nil {
+		return path
+	}
+	return filepath.Join("/", rel)
+}
@@ -0,0 +1,41 @@
+package card
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Recorder(t *testing.T) {
+	r := NewRecorder(mapReader{
+		"/testdata/note.md":   "NOTE",
+		"/testdata/image.png": "IMAGE",
+	}, "/testdata")
+
+	for _, path := range []string{"/testdata/note.md", "/testdata/image.png"} {
+		rc, err := r.Read(path)
+		require.NoError(t, err)
+		_, err = io.ReadAll(rc)
+		require.NoError(t, err)
+	}
+	_, err := r.Read("/testdata/audio/word.mp3")
+	assert.Error(t, err)
+
+	want := map[string]string{
+		"/image.png":      hashContent([]byte("IMAGE")),
+		"/audio/word.mp3": "",
+	}
+	assert.Equal(t, want, r.Hashes([]string{"/note.md"}))
+}
+
+func Test_HashDependencies(t *testing.T) {
+	r := mapReader{"/testdata/image.png": "IMAGE"}
+	want := map[string]string{
+		"/image.png":      hashContent([]byte("IMAGE")),
+		"/audio/word.mp3": "",
+	}
+	got := HashDependencies(r, "/testdata", []string{"/image.png", "/audio/word.mp3"})
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,84 @@
+package card
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+)
+
+// Hash returns a hash of the converted card.
+//
+// It covers everything sent to mochi: content, fields, template,
+// position and attachments.
+func (c Card) Hash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "id:%s\x00", c.ID)
+	fmt.Fprintf(h, "filename:%s\x00", c.Filename())
+	fmt.Fprintf(h, "template:%s\x00", c.TemplateID)
+	fmt.Fprintf(h, "position:%s\x00", c.Position)
+	fmt.Fprintf(h, "reverse:%t\x00", c.ReviewReverse)
+	fmt.Fprintf(h, "content:%d:%s\x00", len(c.Content), c.Content)
+
+	keys := make([]string, 0, len(c.Fields))
+	for key := range c.Fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		fmt.Fprintf(h, "field:%s:%d:%s\x00", key, len(c.Fields[key]), c.Fields[key])
+	}
+
+	for _, attachment := range c.Attachments {
+		sum := sha256.Sum256(attachment.Bytes)
+		fmt.Fprintf(h, "attachment:%s:%x\x00", attachment.Filename, sum)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashFiles returns the hashes of the source files indexed by filename.
+//
+// The fingerprint of the deck config is part of the hash so that
+// a change of parser or template invalidates the files.
+func HashFiles(r Reader, workspace, fingerprint string, filePaths []string) (map[string]string, error) {
+	hashes := make(map[string]string, len(filePaths))
+	for _, path := range filePaths {
+		hash, err := hashFile(r, fingerprint, filepath.Join(workspace, path))
+		if err != nil {
+			return nil, err
+		}
+		hashes[filepath.Base(path)] = hash
+	}
+	return hashes, nil
+}
+
+// HashSource returns the hash of a source file whose content is known,
+// e.g. once the card IDs have been inserted.
+func HashSource(fingerprint string, source []byte) string {
+	hash, _ := hashSource(fingerprint, bytes.NewReader(source))
+	return hash
+}
+
+func hashFile(r Reader, fingerprint, path string) (string, error) {
+	rc, err := r.Read(path)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	return hashSource(fingerprint, rc)
+}
+
+func hashSource(fingerprint string, r io.Reader) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "config:%s\x00", fingerprint)
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,71 @@
+package card
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/internal/converter"
+	"github.com/leonhfr/mochi/internal/parser"
+)
+
+func Test_Card_Hash(t *testing.T) {
+	base := Card{
+		Card: parser.Card{
+			Content:  "CONTENT",
+			Fields:   map[string]string{"name": "NAME", "notes": "NOTES"},
+			Path:     "/testdata/lorem-ipsum.md",
+			Position: "POSITION",
+		},
+		Attachments: []converter.Attachment{{Filename: "image.png", Bytes: []byte("IMAGE")}},
+	}
+
+	same := base
+	same.Fields = map[string]string{"notes": "NOTES", "name": "NAME"}
+	assert.Equal(t, base.Hash(), same.Hash())
+
+	content := base
+	content.Content = "OTHER"
+	assert.NotEqual(t, base.Hash(), content.Hash())
+
+	fields := base
+	fields.Fields = map[string]string{"name": "NAME"}
+	assert.NotEqual(t, base.Hash(), fields.Hash())
+
+	attachments := base
+	attachments.Attachments = []converter.Attachment{{Filename: "image.png", Bytes: []byte("OTHER")}}
+	assert.NotEqual(t, base.Hash(), attachments.Hash())
+}
+
+func Test_HashFiles(t *testing.T) {
+	r := mapReader{
+		"/testdata/lorem-ipsum.md": "CONTENT",
+		"/testdata/sub/dolor.md":   "CONTENT",
+	}
+
+	got, err := HashFiles(r, "/testdata", "note", []string{"/lorem-ipsum.md", "/sub/dolor.md"})
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, got["lorem-ipsum.md"], got["dolor.md"])
+	assert.Equal(t, got["lorem-ipsum.md"], HashSource("note", []byte("CONTENT")))
+
+	other, err := HashFiles(r, "/testdata", "headings", []string{"/lorem-ipsum.md"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, got["lorem-ipsum.md"], other["lorem-ipsum.md"])
+
+	_, err = HashFiles(r, "/testdata", "note", []string{"/missing.md"})
+	assert.Error(t, err)
+}
+
+type mapReader map[string]string
+
+func (r mapReader) Read(path string) (io.ReadCloser, error) {
+	content, ok := r[path]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
@@ -1,21 +1,30 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
 	"path/filepath"
 	"slices"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultRateLimit is the default number of requests per second.
+const DefaultRateLimit = 50
+
 const (
-	configName       = "mochi"
-	defaultRateLimit = 50
-	defaultRootName  = "Root Deck"
+	configName           = "mochi"
+	defaultRootName      = "Root Deck"
+	defaultDeletion      = DeletionDelete
+	defaultPluginTimeout = 10 * time.Second
 )
 
 var configExtensions = [2]string{"yaml", "yml"}
@@ -23,27 +32,109 @@ var configExtensions = [2]string{"yaml", "yml"}
 // ErrNoConfig is the error returned when no config is found in the target directory.
 var ErrNoConfig = errors.New("no config found in target")
 
+// Deletion represents the policy applied to cards whose source vanished.
+type Deletion string
+
+// Deletion policies.
+const (
+	DeletionDelete  Deletion = "delete"
+	DeletionArchive Deletion = "archive"
+	DeletionKeep    Deletion = "keep"
+)
+
 // Config represents a config.
 type Config struct {
 	RateLimit  int                           `yaml:"rateLimit"` // requests per second
 	RootName   string                        `yaml:"rootName"`
 	SkipRoot   bool                          `yaml:"skipRoot"`
-	Decks      []Deck                        `yaml:"decks" validate:"required,dive"` // sorted by longest Path (more specific first)
-	Vocabulary map[string]VocabularyTemplate `yaml:"vocabulary" validate:"dive"`     // map[vocabulary name]template id
+	CardIDs    bool                          `yaml:"cardIDs"`                                                 // embed persistent card IDs in the source files
+	Deletion   Deletion                      `yaml:"deletion" validate:"omitempty,oneof=delete archive keep"` // defaults to delete
+	Threshold  Threshold                     `yaml:"deletionThreshold"`                                       // disabled by default
+	Decks      []Deck                        `yaml:"decks" validate:"required,dive"`                          // sorted by longest Path (more specific first)
+	Vocabulary map[string]VocabularyTemplate `yaml:"vocabulary" validate:"dive"`                              // map[vocabulary name]template id
+	Tables     map[string]TableTemplate      `yaml:"tables" validate:"dive"`                                  // map[table name]template
+	Glossaries map[string]GlossaryTemplate   `yaml:"glossaries" validate:"dive"`                              // map[parser name]template
+	Patterns   map[string]PatternParser      `yaml:"patterns" validate:"dive"`                                // map[parser name]parser
+	Plugins    map[string]PluginParser       `yaml:"plugins" validate:"dive"`                                 // map[parser name]plugin
 }
 
 // Deck represents a sync config.
 type Deck struct {
-	Path   string `yaml:"path" validate:"required"`
-	Name   string `yaml:"name"`
-	Parser string `yaml:"parser"`
+	Path      string   `yaml:"path" validate:"required"`
+	Name      string   `yaml:"name"`
+	Parser    string   `yaml:"parser"`
+	Deletion  Deletion `yaml:"deletion" validate:"omitempty,oneof=delete archive keep"` // defaults to the global policy
+	DataFiles bool     `yaml:"dataFiles"`                                               // parse the CSV, TSV, JSON and YAML files as tables
+}
+
+// Threshold represents the maximum number of cards a sync may delete or archive.
+//
+// A zero value disables the corresponding check.
+type Threshold struct {
+	Count   int     `yaml:"count" validate:"gte=0"`
+	Percent float64 `yaml:"percent" validate:"gte=0,lte=100"` // of the cards tracked in the lockfile
 }
 
 // VocabularyTemplate represents a vocabulary template.
 type VocabularyTemplate struct {
-	TemplateID string `yaml:"templateID" validate:"required"`
-	ExamplesID string `yaml:"examplesID"`
-	NotesID    string `yaml:"notesID"`
+	TemplateID      string `yaml:"templateID" validate:"required"`
+	ExamplesID      string `yaml:"examplesID"`
+	NotesID         string `yaml:"notesID"`
+	PronunciationID string `yaml:"pronunciationID"` // field of the audio file named after the word
+}
+
+// TableTemplate represents a table template.
+//
+// Each row fills the fields of the template from its columns.
+type TableTemplate struct {
+	TemplateID string            `yaml:"templateID" validate:"required"`
+	Name       string            `yaml:"name" validate:"required"` // column used as the card name and identity
+	Fields     map[string]string `yaml:"fields"`                   // map[column header]field id
+}
+
+// GlossaryTemplate represents a glossary template.
+//
+// Each term of a definition list fills the fields of the template,
+// or returns a card whose sides are the term and its definition.
+type GlossaryTemplate struct {
+	TemplateID string `yaml:"templateID"`
+	Term       string `yaml:"term" validate:"required_with=TemplateID"`       // field id of the term
+	Definition string `yaml:"definition" validate:"required_with=TemplateID"` // field id of the definition
+	Reverse    bool   `yaml:"reverse"`                                        // review the cards in both directions
+}
+
+// PatternParser represents a parser declared in the config.
+//
+// The source is split into blocks by the separator, and the pattern is
+// matched against each block, or against the whole source without separator.
+// Without pattern, the block is captured in the content group.
+type PatternParser struct {
+	Separator  string            `yaml:"separator" validate:"required_without=Pattern"`
+	Pattern    string            `yaml:"pattern" validate:"required_without=Separator"` // regexp with named groups
+	Name       string            `yaml:"name"`                                          // group used as the card name
+	Sides      []string          `yaml:"sides"`                                         // groups joined by the side separator
+	Fields     map[string]string `yaml:"fields"`                                        // map[group]field id
+	TemplateID string            `yaml:"templateID"`
+}
+
+// PluginParser represents a parser running an external executable.
+//
+// The executable receives the file as JSON on its stdin
+// and writes the cards as JSON on its stdout.
+type PluginParser struct {
+	Command    string        `yaml:"command" validate:"required"` // looked up in the PATH, then in the workspace
+	Args       []string      `yaml:"args"`
+	Extensions []string      `yaml:"extensions" validate:"dive,startswith=."` // additional file extensions, e.g. [".pgn"]
+	Timeout    time.Duration `yaml:"timeout" validate:"gte=0"`                // defaults to 10s
+}
+
+// Filenames returns the names the config file may have.
+func Filenames() []string {
+	names := make([]string, 0, len(configExtensions))
+	for _, ext := range configExtensions {
+		names = append(names, fmt.Sprintf("%s.%s", configName, ext))
+	}
+	return names
 }
 
 // Reader represents the interface to read a config file.
@@ -53,8 +144,8 @@ type Reader interface {
 
 // Parse parses the config in the target directory.
 func Parse(reader Reader, target string, parsers []string) (*Config, error) {
-	for _, ext := range configExtensions {
-		path := filepath.Join(target, fmt.Sprintf("%s.%s", configName, ext))
+	for _, name := range Filenames() {
+		path := filepath.Join(target, name)
 		rc, err := reader.Read(path)
 		if err == fs.ErrNotExist {
 			continue
@@ -78,6 +169,14 @@ func parseConfig(r io.Reader, parsers []string) (*Config, error) {
 		return nil, err
 	}
 
+	return Validate(config, parsers)
+}
+
+// Validate validates a config and fills in its default values.
+//
+// The parsers are the names accepted as deck parsers.
+// The config is copied and left untouched.
+func Validate(config Config, parsers []string) (*Config, error) {
 	validate := validator.New()
 	validate.RegisterStructValidation(parsersValidator(parsers), Config{})
 	if err := validate.Struct(&config); err != nil {
@@ -89,17 +188,34 @@ func parseConfig(r io.Reader, parsers []string) (*Config, error) {
 }
 
 func cleanConfig(config Config) Config {
+	config.Decks = slices.Clone(config.Decks)
+	config.Plugins = maps.Clone(config.Plugins)
+
 	if config.RateLimit <= 0 {
-		config.RateLimit = defaultRateLimit
+		config.RateLimit = DefaultRateLimit
 	}
 
 	if config.RootName == "" {
 		config.RootName = defaultRootName
 	}
 
+	if config.Deletion == "" {
+		config.Deletion = defaultDeletion
+	}
+
+	for name, plugin := range config.Plugins {
+		if plugin.Timeout == 0 {
+			plugin.Timeout = defaultPluginTimeout
+			config.Plugins[name] = plugin
+		}
+	}
+
 	for i, deck := range config.Decks {
 		path := filepath.Clean(filepath.Join("/", deck.Path))
 		config.Decks[i].Path = path
+		if deck.Deletion == "" {
+			config.Decks[i].Deletion = config.Deletion
+		}
 	}
 
 	slices.SortFunc(config.Decks, func(a, b Deck) int {
@@ -109,12 +225,33 @@ func cleanConfig(config Config) Config {
 	return config
 }
 
+// Fingerprint returns a hash of the config the cards of a deck depend on:
+// the deck config itself and the templates and parsers it may use.
+func (c *Config) Fingerprint(deck Deck) string {
+	h := sha256.New()
+	_ = json.NewEncoder(h).Encode(struct {
+		Deck       Deck
+		CardIDs    bool
+		Vocabulary map[string]VocabularyTemplate
+		Tables     map[string]TableTemplate
+		Glossaries map[string]GlossaryTemplate
+		Patterns   map[string]PatternParser
+		Plugins    map[string]PluginParser
+	}{deck, c.CardIDs, c.Vocabulary, c.Tables, c.Glossaries, c.Patterns, c.Plugins})
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DataFiles reports whether a deck parses the data files.
+func (c *Config) DataFiles() bool {
+	return slices.ContainsFunc(c.Decks, func(deck Deck) bool { return deck.DataFiles })
+}
+
 // Deck returns the deck config that matches the path.
 func (c *Config) Deck(path string) (Deck, bool) {
 	if path == "/" && c.SkipRoot {
 		return Deck{}, false
 	} else if path == "/" {
-		return Deck{Path: "/", Name: c.RootName}, true
+		return Deck{Path: "/", Name: c.RootName, Deletion: c.Deletion}, true
 	}
 
 	for _, deck := range c.Decks {
@@ -132,6 +269,18 @@ func parsersValidator(parsers []string) validator.StructLevelFunc {
 		for vocabularyParser := range config.Vocabulary {
 			parserNames = append(parserNames, vocabularyParser)
 		}
+		for tableParser := range config.Tables {
+			parserNames = append(parserNames, tableParser)
+		}
+		for glossaryParser := range config.Glossaries {
+			parserNames = append(parserNames, glossaryParser)
+		}
+		for patternParser := range config.Patterns {
+			parserNames = append(parserNames, patternParser)
+		}
+		for pluginParser := range config.Plugins {
+			parserNames = append(parserNames, pluginParser)
+		}
 		for _, deck := range config.Decks {
 			if deck.Parser != "" && !slices.Contains(parserNames, deck.Parser) {
 				sl.ReportError(deck.Parser, "parser", "Parser", "not found", "")
@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -46,9 +47,9 @@ func Test_Parse(t *testing.T) {
 					file: "rootName: ROOT_NAME\ndecks:\n  - path: sed-interdum-libero\n    name: Sed interdum libero\n  - path: lorem-ipsum\n    name: Lorem ipsum\n",
 				},
 			},
-			want: &Config{RateLimit: 50, RootName: "ROOT_NAME", Decks: []Deck{
-				{Path: "/sed-interdum-libero", Name: "Sed interdum libero"},
-				{Path: "/lorem-ipsum", Name: "Lorem ipsum"},
+			want: &Config{RateLimit: 50, RootName: "ROOT_NAME", Deletion: DeletionDelete, Decks: []Deck{
+				{Path: "/sed-interdum-libero", Name: "Sed interdum libero", Deletion: DeletionDelete},
+				{Path: "/lorem-ipsum", Name: "Lorem ipsum", Deletion: DeletionDelete},
 			}},
 		},
 		{
@@ -65,7 +66,7 @@ func Test_Parse(t *testing.T) {
 					file: "rootName: ROOT_NAME\ndecks:\n  - path: lorem-ipsum\n",
 				},
 			},
-			want: &Config{RateLimit: 50, RootName: "ROOT_NAME", Decks: []Deck{{Path: "/lorem-ipsum"}}},
+			want: &Config{RateLimit: 50, RootName: "ROOT_NAME", Deletion: DeletionDelete, Decks: []Deck{{Path: "/lorem-ipsum", Deletion: DeletionDelete}}},
 		},
 		{
 			name:    "should set default root deck name",
@@ -81,7 +82,181 @@ func Test_Parse(t *testing.T) {
 					file: "decks:\n  - path: lorem-ipsum\n    name: Lorem ipsum\n",
 				},
 			},
-			want: &Config{RateLimit: 50, RootName: "Root Deck", Decks: []Deck{{Path: "/lorem-ipsum", Name: "Lorem ipsum"}}},
+			want: &Config{RateLimit: 50, RootName: "Root Deck", Deletion: DeletionDelete, Decks: []Deck{{Path: "/lorem-ipsum", Name: "Lorem ipsum", Deletion: DeletionDelete}}},
+		},
+		{
+			name:    "should override the global deletion policy",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "deletion: archive\ndecks:\n  - path: lorem-ipsum\n  - path: sed-interdum-libero\n    deletion: keep\n",
+				},
+			},
+			want: &Config{RateLimit: 50, RootName: "Root Deck", Deletion: DeletionArchive, Decks: []Deck{
+				{Path: "/sed-interdum-libero", Deletion: DeletionKeep},
+				{Path: "/lorem-ipsum", Deletion: DeletionArchive},
+			}},
+		},
+		{
+			name:    "should parse the deletion threshold",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "deletionThreshold:\n  count: 20\n  percent: 12.5\ndecks:\n  - path: lorem-ipsum\n",
+				},
+			},
+			want: &Config{RateLimit: 50, RootName: "Root Deck", Deletion: DeletionDelete, Threshold: Threshold{Count: 20, Percent: 12.5}, Decks: []Deck{
+				{Path: "/lorem-ipsum", Deletion: DeletionDelete},
+			}},
+		},
+		{
+			name:    "should accept table template parsers",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "tables:\n  verbs:\n    templateID: TEMPLATE_ID\n    name: Infinitive\n    fields:\n      English: ENGLISH_ID\ndecks:\n  - path: verbs\n    parser: verbs\n    dataFiles: true\n",
+				},
+			},
+			want: &Config{
+				RateLimit: 50, RootName: "Root Deck", Deletion: DeletionDelete,
+				Decks: []Deck{{Path: "/verbs", Parser: "verbs", Deletion: DeletionDelete, DataFiles: true}},
+				Tables: map[string]TableTemplate{
+					"verbs": {TemplateID: "TEMPLATE_ID", Name: "Infinitive", Fields: map[string]string{"English": "ENGLISH_ID"}},
+				},
+			},
+		},
+		{
+			name:    "should accept glossary parsers",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "glossaries:\n  terms:\n    templateID: TEMPLATE_ID\n    term: TERM_ID\n    definition: DEFINITION_ID\n    reverse: true\ndecks:\n  - path: glossary\n    parser: terms\n",
+				},
+			},
+			want: &Config{
+				RateLimit: 50, RootName: "Root Deck", Deletion: DeletionDelete,
+				Decks: []Deck{{Path: "/glossary", Parser: "terms", Deletion: DeletionDelete}},
+				Glossaries: map[string]GlossaryTemplate{
+					"terms": {TemplateID: "TEMPLATE_ID", Term: "TERM_ID", Definition: "DEFINITION_ID", Reverse: true},
+				},
+			},
+		},
+		{
+			name:    "should accept pattern parsers",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "patterns:\n  arrows:\n    pattern: (?P<front>.+) => (?P<back>.+)\n    sides: [front, back]\ndecks:\n  - path: words\n    parser: arrows\n",
+				},
+			},
+			want: &Config{
+				RateLimit: 50, RootName: "Root Deck", Deletion: DeletionDelete,
+				Decks: []Deck{{Path: "/words", Parser: "arrows", Deletion: DeletionDelete}},
+				Patterns: map[string]PatternParser{
+					"arrows": {Pattern: "(?P<front>.+) => (?P<back>.+)", Sides: []string{"front", "back"}},
+				},
+			},
+		},
+		{
+			name:    "should accept plugin parsers",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "plugins:\n  chess:\n    command: ./bin/pgn\n    extensions: [.pgn]\n  chemistry:\n    command: chem\n    args: [--cards]\n    timeout: 1m\ndecks:\n  - path: chess\n    parser: chess\n",
+				},
+			},
+			want: &Config{
+				RateLimit: 50, RootName: "Root Deck", Deletion: DeletionDelete,
+				Decks: []Deck{{Path: "/chess", Parser: "chess", Deletion: DeletionDelete}},
+				Plugins: map[string]PluginParser{
+					"chess":     {Command: "./bin/pgn", Extensions: []string{".pgn"}, Timeout: 10 * time.Second},
+					"chemistry": {Command: "chem", Args: []string{"--cards"}, Timeout: time.Minute},
+				},
+			},
+		},
+		{
+			name:    "invalid plugin extension",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "plugins:\n  chess:\n    command: ./bin/pgn\n    extensions: [pgn]\ndecks:\n  - path: chess\n",
+				},
+			},
+			err: true,
+		},
+		{
+			name:    "invalid pattern parser",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "patterns:\n  arrows:\n    name: front\ndecks:\n  - path: words\n    parser: arrows\n",
+				},
+			},
+			err: true,
+		},
+		{
+			name:    "invalid glossary template",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "glossaries:\n  terms:\n    templateID: TEMPLATE_ID\n    term: TERM_ID\ndecks:\n  - path: glossary\n    parser: terms\n",
+				},
+			},
+			err: true,
+		},
+		{
+			name:    "invalid table template",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "tables:\n  verbs:\n    templateID: TEMPLATE_ID\ndecks:\n  - path: verbs\n    parser: verbs\n",
+				},
+			},
+			err: true,
+		},
+		{
+			name:    "invalid deletion threshold",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "deletionThreshold:\n  percent: 120\ndecks:\n  - path: lorem-ipsum\n",
+				},
+			},
+			err: true,
+		},
+		{
+			name:    "invalid deletion policy",
+			target:  "testdata",
+			parsers: []string{"note"},
+			read: []testRead{
+				{
+					path: "testdata/mochi.yaml",
+					file: "deletion: forget\ndecks:\n  - path: lorem-ipsum\n",
+				},
+			},
+			err: true,
 		},
 		{
 			name:    "invalid config",
@@ -116,6 +291,27 @@ func Test_Parse(t *testing.T) {
 	}
 }
 
+func Test_Validate(t *testing.T) {
+	config := Config{Decks: []Deck{
+		{Path: "a"},
+		{Path: "/b/c/", Parser: "headings", Deletion: DeletionKeep},
+	}}
+
+	got, err := Validate(config, []string{"note", "headings"})
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{
+		RateLimit: 50, RootName: "Root Deck", Deletion: DeletionDelete,
+		Decks: []Deck{
+			{Path: "/b/c", Parser: "headings", Deletion: DeletionKeep},
+			{Path: "/a", Deletion: DeletionDelete},
+		},
+	}, got)
+	assert.Equal(t, "a", config.Decks[0].Path, "the config is left untouched")
+
+	_, err = Validate(Config{Decks: []Deck{{Path: "a", Parser: "unknown"}}}, []string{"note"})
+	assert.Error(t, err)
+}
+
 func Test_Config_Deck(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -195,6 +391,11 @@ func Test_Config_Deck(t *testing.T) {
 	}
 }
 
+func Test_Config_DataFiles(t *testing.T) {
+	assert.False(t, (&Config{Decks: []Deck{{Path: "/german"}}}).DataFiles())
+	assert.True(t, (&Config{Decks: []Deck{{Path: "/german"}, {Path: "/words", DataFiles: true}}}).DataFiles())
+}
+
 type mockFile struct {
 	mock.Mock
 }
@@ -204,3 +405,14 @@ func (m *mockFile) Read(p string) (io.ReadCloser, error) {
 	rc := strings.NewReader(args.String(0))
 	return io.NopCloser(rc), args.Error(1)
 }
+
+func Test_Config_Fingerprint(t *testing.T) {
+	deck := Deck{Path: "/german", Parser: "german"}
+	config := &Config{Vocabulary: map[string]VocabularyTemplate{"german": {TemplateID: "TEMPLATE_ID"}}}
+	same := &Config{Vocabulary: map[string]VocabularyTemplate{"german": {TemplateID: "TEMPLATE_ID"}}}
+	template := &Config{Vocabulary: map[string]VocabularyTemplate{"german": {TemplateID: "TEMPLATE_ID", NotesID: "NOTES_ID"}}}
+
+	assert.Equal(t, config.Fingerprint(deck), same.Fingerprint(deck))
+	assert.NotEqual(t, config.Fingerprint(deck), template.Fingerprint(deck))
+	assert.NotEqual(t, config.Fingerprint(deck), config.Fingerprint(Deck{Path: "/german", Parser: "note"}))
+}
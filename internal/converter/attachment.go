@@ -2,8 +2,7 @@ package converter
 
 import (
 	"bytes"
-	//nolint:gosec
-	"crypto/md5"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -13,21 +12,28 @@ import (
 const fileNameLength = 16
 
 // Attachment represents an attachment.
+//
+// The filename is derived from the content, so that the same file is
+// uploaded once and an edited file is uploaded again.
 type Attachment struct {
 	Bytes    []byte
 	Filename string
 }
 
 func newAttachment(reader Reader, path, destination string) (Attachment, error) {
-	absPath := filepath.Join(filepath.Dir(path), destination)
+	return newFileAttachment(reader, filepath.Join(filepath.Dir(path), destination))
+}
+
+// newFileAttachment returns the attachment of the file at the absolute path.
+func newFileAttachment(reader Reader, absPath string) (Attachment, error) {
 	bytes, err := readAttachment(reader, absPath)
 	if err != nil {
 		return Attachment{}, err
 	}
 
-	extension := getExtension(destination)
-	pathHash := getPathHash(absPath)
-	filename := getFilename(pathHash, extension)
+	extension := getExtension(absPath)
+	contentHash := getContentHash(bytes)
+	filename := getFilename(contentHash, extension)
 
 	return Attachment{
 		Bytes:    bytes,
@@ -59,12 +65,11 @@ func getExtension(destination string) string {
 	return strings.TrimLeft(filepath.Ext(destination), ".")
 }
 
-func getPathHash(absPath string) string {
-	//nolint:gosec
-	return fmt.Sprintf("%x", md5.Sum([]byte(absPath)))
+func getContentHash(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
 }
 
-func getFilename(pathHash, extension string) string {
-	shortHash := pathHash[:fileNameLength]
+func getFilename(contentHash, extension string) string {
+	shortHash := contentHash[:fileNameLength]
 	return fmt.Sprintf("%s.%s", shortHash, extension)
 }
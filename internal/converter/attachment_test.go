@@ -34,7 +34,7 @@ func Test_newAttachment(t *testing.T) {
 			destination: "scream.png",
 			want: Attachment{
 				Bytes:    []byte("IMAGE CONTENT"),
-				Filename: "22abb8f07c02970e.png",
+				Filename: "7190e29480a9081f.png",
 			},
 		},
 	}
@@ -0,0 +1,32 @@
+package converter
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var audioExtensions = []string{".mp3", ".ogg", ".wav"}
+
+// ConvertAudio returns the audio file named from the note at path as mochi markdown.
+//
+// The name has no extension, the first audio file found is returned.
+func (c *Converter) ConvertAudio(reader Reader, path, name string) (Result, bool) {
+	for _, extension := range audioExtensions {
+		attachment, err := newFileAttachment(reader, c.find(path, name+extension))
+		if err != nil {
+			continue
+		}
+
+		return Result{
+			Markdown:    fmt.Sprintf("![](%s)", attachment.destination()),
+			Attachments: []Attachment{attachment},
+		}, true
+	}
+	return Result{}, false
+}
+
+func isAudio(destination string) bool {
+	return slices.Contains(audioExtensions, strings.ToLower(filepath.Ext(destination)))
+}
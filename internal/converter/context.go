@@ -1,18 +1,27 @@
 package converter
 
-import "github.com/yuin/goldmark/parser"
+import (
+	"slices"
+
+	"github.com/yuin/goldmark/parser"
+)
 
 var (
 	readerKey      = parser.NewContextKey()
+	converterKey   = parser.NewContextKey()
 	pathKey        = parser.NewContextKey()
+	embedsKey      = parser.NewContextKey()
 	errorKey       = parser.NewContextKey()
 	attachmentsKey = parser.NewContextKey()
+	pendingKey     = parser.NewContextKey()
 )
 
-func newContext(reader Reader, path string) parser.Context {
+func newContext(reader Reader, converter *Converter, path string, embeds []string) parser.Context {
 	ctx := parser.NewContext()
 	ctx.Set(readerKey, reader)
+	ctx.Set(converterKey, converter)
 	ctx.Set(pathKey, path)
+	ctx.Set(embedsKey, embeds)
 	return ctx
 }
 
@@ -24,6 +33,14 @@ func getReader(pc parser.Context) Reader {
 	return v.(Reader)
 }
 
+func getConverter(pc parser.Context) *Converter {
+	v := pc.Get(converterKey)
+	if v == nil {
+		return nil
+	}
+	return v.(*Converter)
+}
+
 func getPath(pc parser.Context) string {
 	v := pc.Get(pathKey)
 	if v == nil {
@@ -32,6 +49,15 @@ func getPath(pc parser.Context) string {
 	return v.(string)
 }
 
+// getEmbeds returns the notes being embedded, to detect the cycles.
+func getEmbeds(pc parser.Context) []string {
+	v := pc.Get(embedsKey)
+	if v == nil {
+		return nil
+	}
+	return v.([]string)
+}
+
 func getError(pc parser.Context) error {
 	v := pc.Get(errorKey)
 	if v == nil {
@@ -48,7 +74,24 @@ func getAttachments(pc parser.Context) []Attachment {
 	return v.([]Attachment)
 }
 
+// addAttachment adds the attachment unless a file with the same content was added.
 func addAttachment(pc parser.Context, attachment Attachment) {
 	attachments := getAttachments(pc)
+	if slices.ContainsFunc(attachments, func(a Attachment) bool { return a.Filename == attachment.Filename }) {
+		return
+	}
 	pc.Set(attachmentsKey, append(attachments, attachment))
 }
+
+func getPending(pc parser.Context) bool {
+	v := pc.Get(pendingKey)
+	if v == nil {
+		return false
+	}
+	return v.(bool)
+}
+
+// setPending records a link to a card that is not synced yet.
+func setPending(pc parser.Context) {
+	pc.Set(pendingKey, true)
+}
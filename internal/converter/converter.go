@@ -10,6 +10,8 @@ import (
 	"github.com/yuin/goldmark/util"
 
 	"github.com/leonhfr/mochi/internal/converter/heading"
+	"github.com/leonhfr/mochi/internal/converter/latex"
+	"github.com/leonhfr/mochi/internal/converter/wikilink"
 	"github.com/leonhfr/mochi/internal/converter/youtube"
 )
 
@@ -18,19 +20,55 @@ type Reader interface {
 	Read(path string) (io.ReadCloser, error)
 }
 
+// Resolver represents the interface to resolve the links between notes.
+//
+// It returns the ID of the card the link targets in the note at path.
+// The ID is empty when the card exists in the workspace but is not synced yet.
+type Resolver interface {
+	Resolve(path, note, heading string) (string, bool)
+}
+
+// Logger is the interface to log output.
+type Logger interface {
+	Infof(format string, args ...any)
+}
+
+// Vault represents the interface to find the files of the workspace by name.
+//
+// It returns the absolute path of the file named from the note at path.
+type Vault interface {
+	Find(path, name string) (string, bool)
+}
+
 // Result represents the result of a conversion.
 type Result struct {
 	Markdown    string
 	Attachments []Attachment
+	Pending     bool // links to cards not synced yet, converted to text
 }
 
 // Converter converts markdown to mochi markdown.
 type Converter struct {
 	markdown goldmark.Markdown
+	resolver Resolver
+	vault    Vault
+	logger   Logger
 }
 
 // New returns a new Converter.
-func New() *Converter {
+func New(options ...Option) *Converter {
+	c := &config{
+		extensions: []goldmark.Extender{
+			heading.New(),
+			youtube.New(),
+			wikilink.New(),
+			latex.New(),
+		},
+	}
+	for _, option := range options {
+		option(c)
+	}
+
 	return &Converter{
 		markdown: goldmark.New(
 			goldmark.WithRenderer(markdown.NewRenderer()),
@@ -39,17 +77,66 @@ func New() *Converter {
 					util.Prioritized(newTransformer(), 999),
 				),
 			),
-			goldmark.WithExtensions(
-				heading.New(),
-				youtube.New(),
-			),
+			goldmark.WithExtensions(c.extensions...),
 		),
+		resolver: c.resolver,
+		vault:    c.vault,
+		logger:   c.logger,
+	}
+}
+
+type config struct {
+	extensions []goldmark.Extender
+	resolver   Resolver
+	vault      Vault
+	logger     Logger
+}
+
+// Option represents an option for the converter.
+type Option func(*config)
+
+// WithExtensions adds goldmark extensions to the converter.
+//
+// They are added after the default extensions.
+func WithExtensions(extensions ...goldmark.Extender) Option {
+	return func(c *config) {
+		c.extensions = append(c.extensions, extensions...)
+	}
+}
+
+// WithLinks resolves the [[Note]] and [[Note#Heading]] links between notes.
+//
+// The links resolved to a card become mochi card links,
+// the others are converted to text.
+func WithLinks(resolver Resolver) Option {
+	return func(c *config) {
+		c.resolver = resolver
+	}
+}
+
+// WithVault finds the embedded files anywhere in the workspace.
+//
+// By default, the embedded files are relative to the note.
+func WithVault(vault Vault) Option {
+	return func(c *config) {
+		c.vault = vault
+	}
+}
+
+// WithLogger logs a warning for each embed that cannot be resolved.
+func WithLogger(logger Logger) Option {
+	return func(c *config) {
+		c.logger = logger
 	}
 }
 
 // Convert converts the source markdown to mochi markdown.
 func (c *Converter) Convert(reader Reader, path, source string) (Result, error) {
-	ctx := newContext(reader, path)
+	return c.convert(reader, path, source, []string{getEmbedKey(path, "")})
+}
+
+func (c *Converter) convert(reader Reader, path, source string, embeds []string) (Result, error) {
+	ctx := newContext(reader, c, path, embeds)
 	b := bytes.NewBuffer(nil)
 	err := c.markdown.Convert([]byte(source), b, parser.WithContext(ctx))
 	if err != nil {
@@ -58,5 +145,6 @@ func (c *Converter) Convert(reader Reader, path, source string) (Result, error)
 	return Result{
 		Markdown:    b.String(),
 		Attachments: getAttachments(ctx),
+		Pending:     getPending(ctx),
 	}, getError(ctx)
 }
@@ -1,7 +1,11 @@
 package converter
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -31,9 +35,40 @@ func Test_Converter_Convert(t *testing.T) {
 			},
 			source: "![Scream](./scream.png)\n",
 			want: Result{
-				Markdown: "![Scream](@media/22abb8f07c02970e.png)\n",
+				Markdown: "![Scream](@media/7190e29480a9081f.png)\n",
 				Attachments: []Attachment{
-					{Bytes: []byte("IMAGE CONTENT"), Filename: "22abb8f07c02970e.png"},
+					{Bytes: []byte("IMAGE CONTENT"), Filename: "7190e29480a9081f.png"},
+				},
+			},
+		},
+		{
+			name: "images with the same content",
+			path: "/testdata/Images.md",
+			calls: []testRead{
+				{path: "/testdata/scream.png", content: "IMAGE CONTENT"},
+				{path: "/testdata/copies/scream.png", content: "IMAGE CONTENT"},
+			},
+			source: "![Scream](./scream.png) ![Copy](copies/scream.png)\n",
+			want: Result{
+				Markdown: "![Scream](@media/7190e29480a9081f.png) ![Copy](@media/7190e29480a9081f.png)\n",
+				Attachments: []Attachment{
+					{Bytes: []byte("IMAGE CONTENT"), Filename: "7190e29480a9081f.png"},
+				},
+			},
+		},
+		{
+			name: "audio",
+			path: "/testdata/Audio.md",
+			calls: []testRead{
+				{path: "/testdata/word.ogg", content: "OGG"},
+				{path: "/testdata/hola.mp3", content: "MP3"},
+			},
+			source: "Listen to [word](word.ogg), ![](hola.mp3) and [a page](page.html).\n",
+			want: Result{
+				Markdown: "Listen to [word](@media/a87e82d0c1558b8a.ogg), ![](@media/b84e48724bb66bb9.mp3) and [a page](page.html).\n",
+				Attachments: []Attachment{
+					{Bytes: []byte("OGG"), Filename: "a87e82d0c1558b8a.ogg"},
+					{Bytes: []byte("MP3"), Filename: "b84e48724bb66bb9.mp3"},
 				},
 			},
 		},
@@ -53,6 +88,48 @@ func Test_Converter_Convert(t *testing.T) {
 				Markdown: "<iframe src=\"https://www.youtube.com/embed/VIDEO?rel=0&amp;autoplay=0&amp;showinfo=0&amp;enablejsapi=0\" frameborder=\"0\" loading=\"lazy\" gesture=\"media\" allow=\"autoplay; fullscreen\" allowautoplay=\"true\" allowfullscreen=\"true\" style=\"aspect-ratio:16/9;height:100%;width:100%;\"></iframe>\n",
 			},
 		},
+		{
+			name:   "inline math",
+			path:   "/testdata/Math.md",
+			source: "Let $a_1 * b_2$ and $x^* = y_*$ be _emphasized_.\n",
+			want:   Result{Markdown: "Let $a_1 * b_2$ and $x^* = y_*$ be *emphasized*.\n"},
+		},
+		{
+			name:   "inline display math",
+			path:   "/testdata/Math.md",
+			source: "Sum $$ \\sum_{i=1}^n i_* $$ inline.\n",
+			want:   Result{Markdown: "Sum $$\\sum_{i=1}^n i_*$$ inline.\n"},
+		},
+		{
+			name:   "math block",
+			path:   "/testdata/Math.md",
+			source: "Matrix:\n\n$$\n\\begin{pmatrix} a_1 & b_* \\\\\nc_2 & d^* \\end{pmatrix}\n$$\n\nAfter.\n",
+			want:   Result{Markdown: "Matrix:\n\n$$\n\\begin{pmatrix} a_1 & b_* \\\\\nc_2 & d^* \\end{pmatrix}\n$$\n\nAfter.\n"},
+		},
+		{
+			name:   "math block on the delimiter lines",
+			path:   "/testdata/Math.md",
+			source: "$$ x_1 = \\\\\ny_* $$\n",
+			want:   Result{Markdown: "$$\nx_1 = \\\\\ny_*\n$$\n"},
+		},
+		{
+			name:   "math block followed by text",
+			path:   "/testdata/Math.md",
+			source: "$$\nx_1\n$$ trailing *text*\n",
+			want:   Result{Markdown: "$$\nx_1\n$$\ntrailing *text*\n"},
+		},
+		{
+			name:   "dollar amounts",
+			path:   "/testdata/Math.md",
+			source: "It costs $5 and $10, or $ 20 $.\n",
+			want:   Result{Markdown: "It costs $5 and $10, or $ 20 $.\n"},
+		},
+		{
+			name:   "math in code",
+			path:   "/testdata/Math.md",
+			source: "`$a_b$` and\n\n```\n$$\nx_1\n$$\n```\n",
+			want:   Result{Markdown: "`$a_b$` and\n\n```\n$$\nx_1\n$$\n```\n"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -67,6 +144,249 @@ func Test_Converter_Convert(t *testing.T) {
 	}
 }
 
+func Test_Converter_Convert_links(t *testing.T) {
+	resolver := testResolver{
+		"Other note":          "CARD_1",
+		"Other note#Heading":  "CARD_2",
+		"Links#Local heading": "CARD_3",
+		"New note":            "",
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		pending bool
+	}{
+		{
+			name:   "note",
+			source: "See [[Other note]].\n",
+			want:   "See [[CARD_1]].\n",
+		},
+		{
+			name:   "heading",
+			source: "See [[Other note#Heading|the heading]].\n",
+			want:   "See [[CARD_2]].\n",
+		},
+		{
+			name:   "current note",
+			source: "See [[#Local heading]].\n",
+			want:   "See [[CARD_3]].\n",
+		},
+		{
+			name:   "unresolved",
+			source: "See [[Unsynced#Heading]] and [[Unsynced|label]].\n",
+			want:   "See Unsynced > Heading and label.\n",
+		},
+		{
+			name:    "pending",
+			source:  "See [[New note]] and [[Other note]].\n",
+			want:    "See New note and [[CARD_1]].\n",
+			pending: true,
+		},
+		{
+			name:   "code span",
+			source: "See `[[Other note]]`.\n",
+			want:   "See `[[Other note]]`.\n",
+		},
+		{
+			name:   "regular link",
+			source: "See [Other note](https://example.com).\n",
+			want:   "See [Other note](https://example.com).\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(WithLinks(resolver))
+			got, err := c.Convert(newMockReader(nil), "/testdata/Links.md", tt.source)
+			assert.NoError(t, err)
+			assert.Equal(t, Result{Markdown: tt.want, Pending: tt.pending}, got)
+		})
+	}
+}
+
+func Test_Converter_Convert_embeds(t *testing.T) {
+	vault := testVault{
+		"cat.png":  "/testdata/images/cat.png",
+		"dog.png":  "/testdata/notes/dog.png",
+		"Other.md": "/testdata/notes/Other.md",
+		"Loop.md":  "/testdata/Loop.md",
+		"word.mp3": "/testdata/audio/word.mp3",
+	}
+
+	other := "---\ntags: [other]\n---\n# Other\n\n## Section\n\nSection content ![[dog.png]].\n\n```\n# Not a heading\n```\n\n### Subsection\n\nNested.\n\n## Next\n\nNext content.\n"
+
+	tests := []struct {
+		name     string
+		calls    []testRead
+		source   string
+		want     Result
+		warnings []string
+	}{
+		{
+			name:   "image",
+			calls:  []testRead{{path: "/testdata/images/cat.png", content: "CAT"}},
+			source: "![[cat.png]]\n",
+			want: Result{
+				Markdown:    "![](@media/15b89a569474240a.png)\n",
+				Attachments: []Attachment{{Bytes: []byte("CAT"), Filename: "15b89a569474240a.png"}},
+			},
+		},
+		{
+			name:   "image size",
+			calls:  []testRead{{path: "/testdata/images/cat.png", content: "CAT"}},
+			source: "![[cat.png|300]] ![[cat.png|A cat]]\n",
+			want: Result{
+				Markdown: "<img src=\"@media/15b89a569474240a.png\" width=\"300\"> ![A cat](@media/15b89a569474240a.png)\n",
+				Attachments: []Attachment{
+					{Bytes: []byte("CAT"), Filename: "15b89a569474240a.png"},
+				},
+			},
+		},
+		{
+			name:   "audio",
+			calls:  []testRead{{path: "/testdata/audio/word.mp3", content: "MP3"}},
+			source: "![[word.mp3]] ![[word.mp3|300]]\n",
+			want: Result{
+				Markdown: "![](@media/b84e48724bb66bb9.mp3) ![](@media/b84e48724bb66bb9.mp3)\n",
+				Attachments: []Attachment{
+					{Bytes: []byte("MP3"), Filename: "b84e48724bb66bb9.mp3"},
+				},
+			},
+		},
+		{
+			name: "note section",
+			calls: []testRead{
+				{path: "/testdata/notes/Other.md", content: other},
+				{path: "/testdata/notes/dog.png", content: "DOG"},
+			},
+			source: "Before.\n\n![[Other#Section]]\n\nAfter.\n",
+			want: Result{
+				Markdown:    "Before.\n\n## Section\n\nSection content ![](@media/6237ac702a421096.png).\n\n```\n# Not a heading\n```\n\n### Subsection\n\nNested.\n\nAfter.\n",
+				Attachments: []Attachment{{Bytes: []byte("DOG"), Filename: "6237ac702a421096.png"}},
+			},
+		},
+		{
+			name: "cycle",
+			calls: []testRead{
+				{path: "/testdata/Loop.md", content: "Loop.\n\n![[Embeds]]\n"},
+			},
+			source: "Embeds.\n\n![[Loop]]\n",
+			want:   Result{Markdown: "Embeds.\n\nLoop.\n\nEmbeds\n"},
+			warnings: []string{
+				"embed(/testdata/Loop.md): warning: ![[Embeds]] creates an embed cycle, converted to text",
+			},
+		},
+		{
+			name:   "unresolved",
+			source: "![[bird.png]] ![[Other#Unknown]]\n",
+			calls: []testRead{
+				{path: "/testdata/bird.png", err: fs.ErrNotExist},
+				{path: "/testdata/notes/Other.md", content: other},
+			},
+			want: Result{Markdown: "bird.png Other > Unknown\n"},
+			warnings: []string{
+				"embed(/testdata/Embeds.md): warning: ![[bird.png]] does not match any file, converted to text",
+				"embed(/testdata/Embeds.md): warning: ![[Other#Unknown]] does not match any heading, converted to text",
+			},
+		},
+		{
+			name:   "unsupported",
+			source: "![[slides.pdf]]\n",
+			want:   Result{Markdown: "slides.pdf\n"},
+			warnings: []string{
+				"embed(/testdata/Embeds.md): warning: ![[slides.pdf]] unsupported file type, converted to text",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMockReader(tt.calls)
+			logger := &testLogger{}
+			c := New(WithVault(vault), WithLogger(logger))
+			got, err := c.Convert(r, "/testdata/Embeds.md", tt.source)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.warnings, logger.lines)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Converter_ConvertAudio(t *testing.T) {
+	tests := []struct {
+		name  string
+		calls []testRead
+		audio string
+		want  Result
+		ok    bool
+	}{
+		{
+			name: "first extension found",
+			calls: []testRead{
+				{path: "/testdata/audio/hola.mp3", err: errors.New("ERROR")},
+				{path: "/testdata/audio/hola.ogg", err: errors.New("ERROR")},
+				{path: "/testdata/audio/hola.wav", content: "WAV"},
+			},
+			audio: "hola",
+			want: Result{
+				Markdown:    "![](@media/c077a665f0da939d.wav)",
+				Attachments: []Attachment{{Bytes: []byte("WAV"), Filename: "c077a665f0da939d.wav"}},
+			},
+			ok: true,
+		},
+		{
+			name: "not found",
+			calls: []testRead{
+				{path: "/testdata/audio/adios.mp3", err: errors.New("ERROR")},
+				{path: "/testdata/audio/adios.ogg", err: errors.New("ERROR")},
+				{path: "/testdata/audio/adios.wav", err: errors.New("ERROR")},
+			},
+			audio: "adios",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMockReader(tt.calls)
+			got, ok := New().ConvertAudio(r, "/testdata/audio/Words.md", tt.audio)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.ok, ok)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+type testVault map[string]string
+
+func (v testVault) Find(_, name string) (string, bool) {
+	absPath, ok := v[name]
+	return absPath, ok
+}
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Infof(format string, args ...any) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+type testResolver map[string]string
+
+func (r testResolver) Resolve(path, note, heading string) (string, bool) {
+	if note == "" {
+		note = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if heading != "" {
+		note += "#" + heading
+	}
+	cardID, ok := r[note]
+	return cardID, ok
+}
+
 type testRead struct {
 	path    string
 	content string
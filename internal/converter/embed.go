@@ -0,0 +1,172 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/adrg/frontmatter"
+	"github.com/yuin/goldmark/parser"
+
+	"github.com/leonhfr/mochi/internal/converter/wikilink"
+)
+
+const markdownExtension = ".md"
+
+var (
+	imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp"}
+	headingRegexp   = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+)
+
+// EmbedExtensions returns the extensions of the files that may be embedded in the notes.
+func EmbedExtensions() []string {
+	return slices.Concat([]string{markdownExtension}, imageExtensions, audioExtensions)
+}
+
+// embed resolves an embedded image, audio file or note.
+//
+// The embeds that cannot be resolved, and the notes already being
+// embedded, are converted to text.
+func (c *Converter) embed(pc parser.Context, link *wikilink.WikiLink) {
+	ext := strings.ToLower(filepath.Ext(link.Note))
+	if slices.Contains(imageExtensions, ext) || isAudio(ext) {
+		c.embedMedia(pc, link)
+	} else if ext == "" || ext == markdownExtension {
+		c.embedNote(pc, link)
+	} else {
+		c.warn(pc, link, "unsupported file type")
+	}
+}
+
+func (c *Converter) embedMedia(pc parser.Context, link *wikilink.WikiLink) {
+	absPath := c.find(getPath(pc), link.Note)
+
+	attachment, err := newFileAttachment(getReader(pc), absPath)
+	if err != nil {
+		c.warn(pc, link, "does not match any file")
+		return
+	}
+
+	addAttachment(pc, attachment)
+	link.Destination = string(attachment.destination())
+	if isAudio(absPath) {
+		link.Width, link.Height = "", ""
+	}
+}
+
+func (c *Converter) embedNote(pc parser.Context, link *wikilink.WikiLink) {
+	reader, path := getReader(pc), getPath(pc)
+	if link.Note != "" {
+		name := strings.TrimSuffix(link.Note, markdownExtension) + markdownExtension
+		path = c.find(path, name)
+	}
+
+	embeds := getEmbeds(pc)
+	key := getEmbedKey(path, link.Heading)
+	if slices.Contains(embeds, key) {
+		c.warn(pc, link, "creates an embed cycle")
+		return
+	}
+
+	source, err := readNote(reader, path)
+	if err != nil {
+		c.warn(pc, link, "does not match any note")
+		return
+	}
+
+	section, ok := getSection(source, link.Heading)
+	if !ok {
+		c.warn(pc, link, "does not match any heading")
+		return
+	}
+
+	result, err := c.convert(reader, path, string(section), append(embeds[:len(embeds):len(embeds)], key))
+	if err != nil {
+		c.warn(pc, link, fmt.Sprintf("cannot be converted: %v", err))
+		return
+	}
+
+	for _, attachment := range result.Attachments {
+		addAttachment(pc, attachment)
+	}
+	if result.Pending {
+		setPending(pc)
+	}
+	link.Content = strings.TrimSpace(result.Markdown)
+}
+
+// find returns the absolute path of the file named from the note at path.
+//
+// Without match in the vault, the path relative to the note is returned, so
+// that the missing file is still read and recorded as a dependency of the note.
+func (c *Converter) find(path, name string) string {
+	if c.vault != nil {
+		if absPath, ok := c.vault.Find(path, name); ok {
+			return absPath
+		}
+	}
+	return filepath.Join(filepath.Dir(path), name)
+}
+
+// warn logs that the embed cannot be resolved and is converted to text.
+func (c *Converter) warn(pc parser.Context, link *wikilink.WikiLink, reason string) {
+	if c.logger == nil {
+		return
+	}
+
+	target := link.Note
+	if link.Heading != "" {
+		target += "#" + link.Heading
+	}
+	c.logger.Infof("embed(%s): warning: ![[%s]] %s, converted to text", getPath(pc), target, reason)
+}
+
+func getEmbedKey(path, heading string) string {
+	return path + "#" + strings.ToLower(heading)
+}
+
+// readNote returns the content of a note without its frontmatter.
+func readNote(reader Reader, path string) ([]byte, error) {
+	raw, err := readAttachment(reader, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var matter map[string]any
+	return frontmatter.Parse(bytes.NewReader(raw), &matter)
+}
+
+// getSection returns the heading and the content until the next heading
+// of the same or a higher level, or the whole source without heading.
+func getSection(source []byte, heading string) ([]byte, bool) {
+	if heading == "" {
+		return source, true
+	}
+
+	lines := strings.SplitAfter(string(source), "\n")
+	start, level, fenced := -1, 0, false
+	for i, line := range lines {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+		}
+		match := headingRegexp.FindStringSubmatch(strings.TrimRight(line, "\n"))
+		if fenced || match == nil {
+			continue
+		}
+
+		switch {
+		case start < 0 && strings.EqualFold(strings.TrimSpace(match[2]), strings.TrimSpace(heading)):
+			start, level = i, len(match[1])
+		case start >= 0 && len(match[1]) <= level:
+			return []byte(strings.Join(lines[start:i], "")), true
+		}
+	}
+
+	if start < 0 {
+		return nil, false
+	}
+	return []byte(strings.Join(lines[start:], "")), true
+}
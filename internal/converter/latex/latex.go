@@ -0,0 +1,272 @@
+package latex
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	inlineDelimiter  = "$"
+	displayDelimiter = "$$"
+)
+
+type latexExtension struct{}
+
+// New returns a new LaTeX extension.
+//
+// It parses the $...$ inline formulas and the $$...$$ display formulas,
+// rendered verbatim so that their content is never escaped.
+func New() goldmark.Extender {
+	return &latexExtension{}
+}
+
+// Extend implements goldmark.Extender.
+func (e *latexExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(
+			util.Prioritized(defaultBlockParser, 90),
+		),
+		parser.WithInlineParsers(
+			util.Prioritized(defaultInlineParser, 90),
+		),
+	)
+	m.Renderer().AddOptions(
+		renderer.WithNodeRenderers(
+			util.Prioritized(NewRenderer(), 500),
+		),
+	)
+}
+
+// InlineMath struct represents a formula written inside a paragraph.
+type InlineMath struct {
+	ast.BaseInline
+	Formula []byte
+	Display bool // whether the formula is delimited by $$
+}
+
+// KindInlineMath is a NodeKind of the InlineMath node.
+var KindInlineMath = ast.NewNodeKind("InlineMath")
+
+// Kind implements Node.Kind.
+func (n *InlineMath) Kind() ast.NodeKind {
+	return KindInlineMath
+}
+
+// Dump implements Node.Dump.
+func (n *InlineMath) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Formula": string(n.Formula)}, nil)
+}
+
+// MathBlock struct represents a display formula.
+type MathBlock struct {
+	ast.BaseBlock
+}
+
+// KindMathBlock is a NodeKind of the MathBlock node.
+var KindMathBlock = ast.NewNodeKind("MathBlock")
+
+// Kind implements Node.Kind.
+func (n *MathBlock) Kind() ast.NodeKind {
+	return KindMathBlock
+}
+
+// IsRaw implements Node.IsRaw.
+func (n *MathBlock) IsRaw() bool {
+	return true
+}
+
+// Dump implements Node.Dump.
+func (n *MathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type inlineParser struct{}
+
+var (
+	defaultInlineParser = &inlineParser{}
+
+	_ parser.InlineParser = (*inlineParser)(nil)
+)
+
+// Trigger implements parser.InlineParser.
+func (p *inlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Parse implements parser.InlineParser.
+//
+// The formula may not start or end with a space, and the closing
+// delimiter may not be followed by a digit, so that prices are kept.
+func (p *inlineParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	delimiter := inlineDelimiter
+	if bytes.HasPrefix(line, []byte(displayDelimiter)) {
+		delimiter = displayDelimiter
+	}
+
+	rest := line[len(delimiter):]
+	for offset := 0; offset < len(rest); {
+		index := bytes.Index(rest[offset:], []byte(delimiter))
+		if index < 0 {
+			return nil
+		}
+		end := offset + index
+		if end > 0 && rest[end-1] == '\\' {
+			offset = end + len(delimiter)
+			continue
+		}
+
+		formula := rest[:end]
+		after := rest[end+len(delimiter):]
+		if len(bytes.TrimSpace(formula)) == 0 ||
+			(delimiter == inlineDelimiter && (isSpace(formula[0]) || isSpace(formula[len(formula)-1]) || startsWithDigit(after))) {
+			return nil
+		}
+
+		block.Advance(len(delimiter) + end + len(delimiter))
+		if delimiter == displayDelimiter {
+			formula = bytes.TrimSpace(formula)
+		}
+		return &InlineMath{Formula: bytes.Clone(formula), Display: delimiter == displayDelimiter}
+	}
+	return nil
+}
+
+type blockParser struct{}
+
+var (
+	defaultBlockParser = &blockParser{}
+
+	_ parser.BlockParser = (*blockParser)(nil)
+)
+
+// Trigger implements parser.BlockParser.
+func (p *blockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+// Open implements parser.BlockParser.
+//
+// The display formulas start with a line beginning with $$, the
+// formulas written on a single line being parsed as inline formulas.
+func (p *blockParser) Open(_ ast.Node, reader text.Reader, _ parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	trimmed := bytes.TrimSpace(line)
+	if !bytes.HasPrefix(trimmed, []byte(displayDelimiter)) {
+		return nil, parser.NoChildren
+	}
+
+	rest := bytes.TrimSpace(trimmed[len(displayDelimiter):])
+	if bytes.HasSuffix(rest, []byte(displayDelimiter)) {
+		return nil, parser.NoChildren
+	}
+
+	node := &MathBlock{}
+	if len(rest) > 0 {
+		start := segment.Start + bytes.Index(line, rest)
+		node.Lines().Append(text.NewSegment(start, segment.Stop))
+	}
+	reader.Advance(segment.Len() - 1)
+	return node, parser.NoChildren
+}
+
+// Continue implements parser.BlockParser.
+//
+// The formula ends with the first $$, the rest of the line
+// being parsed as the blocks following the formula.
+func (p *blockParser) Continue(node ast.Node, reader text.Reader, _ parser.Context) parser.State {
+	line, segment := reader.PeekLine()
+	if index := bytes.Index(line, []byte(displayDelimiter)); index >= 0 {
+		if len(bytes.TrimSpace(line[:index])) > 0 {
+			node.Lines().Append(text.NewSegment(segment.Start, segment.Start+index))
+		}
+		end := index + len(displayDelimiter)
+		if len(bytes.TrimSpace(line[end:])) == 0 {
+			end = segment.Len() - 1
+		}
+		reader.Advance(end)
+		return parser.Close
+	}
+
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	return parser.Continue | parser.NoChildren
+}
+
+// Close implements parser.BlockParser.
+func (p *blockParser) Close(_ ast.Node, _ text.Reader, _ parser.Context) {}
+
+// CanInterruptParagraph implements parser.BlockParser.
+func (p *blockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+// CanAcceptIndentedLine implements parser.BlockParser.
+func (p *blockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// Renderer struct is a renderer.NodeRenderer implementation for the extension.
+type Renderer struct{}
+
+// NewRenderer builds a new Renderer and returns it.
+func NewRenderer() renderer.NodeRenderer {
+	return &Renderer{}
+}
+
+// RegisterFuncs implements NodeRenderer.RegisterFuncs.
+func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindInlineMath, r.renderInlineMath)
+	reg.Register(KindMathBlock, r.renderMathBlock)
+}
+
+func (r *Renderer) renderInlineMath(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		n := node.(*InlineMath)
+		delimiter := inlineDelimiter
+		if n.Display {
+			delimiter = displayDelimiter
+		}
+		_, _ = w.Write([]byte(delimiter))
+		_, _ = w.Write(n.Formula)
+		_, _ = w.Write([]byte(delimiter))
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *Renderer) renderMathBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		if w.Buffered() > 0 {
+			_, _ = w.Write([]byte("\n"))
+		}
+		return ast.WalkContinue, nil
+	}
+
+	if node.PreviousSibling() != nil && node.HasBlankPreviousLines() {
+		_, _ = w.Write([]byte("\n"))
+	}
+
+	_, _ = w.Write([]byte(displayDelimiter + "\n"))
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		_, _ = w.Write(bytes.TrimRight(segment.Value(source), "\r\n"))
+		_, _ = w.Write([]byte("\n"))
+	}
+	_, _ = w.Write([]byte(displayDelimiter + "\n"))
+	return ast.WalkSkipChildren, nil
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n'
+}
+
+func startsWithDigit(b []byte) bool {
+	return len(b) > 0 && '0' <= b[0] && b[0] <= '9'
+}
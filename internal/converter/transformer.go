@@ -4,6 +4,8 @@ import (
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
+
+	"github.com/leonhfr/mochi/internal/converter/wikilink"
 )
 
 type transformer struct{}
@@ -14,6 +16,7 @@ func newTransformer() parser.ASTTransformer {
 
 func (t *transformer) Transform(node *ast.Document, _ text.Reader, pc parser.Context) {
 	reader := getReader(pc)
+	converter := getConverter(pc)
 	path := getPath(pc)
 
 	err := ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -28,6 +31,24 @@ func (t *transformer) Transform(node *ast.Document, _ text.Reader, pc parser.Con
 				addAttachment(pc, attachment)
 				node.Destination = attachment.destination()
 			}
+		case *ast.Link:
+			if !isAudio(string(node.Destination)) {
+				break
+			}
+			attachment, err := newAttachment(reader, path, string(node.Destination))
+			if err == nil {
+				addAttachment(pc, attachment)
+				node.Destination = attachment.destination()
+			}
+		case *wikilink.WikiLink:
+			if node.Embed {
+				converter.embed(pc, node)
+			} else if converter.resolver != nil {
+				var ok bool
+				if node.CardID, ok = converter.resolver.Resolve(path, node.Note, node.Heading); ok && node.CardID == "" {
+					setPending(pc)
+				}
+			}
 		}
 
 		return ast.WalkContinue, nil
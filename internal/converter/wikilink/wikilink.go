@@ -0,0 +1,208 @@
+package wikilink
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var sizeRegexp = regexp.MustCompile(`^(\d+)(?:x(\d+))?$`)
+
+type wikiLinkExtension struct{}
+
+// New returns a new WikiLink extension.
+//
+// It parses the [[Note]], [[Note#Heading]] and [[Note|Label]] links.
+// The links are rendered as mochi card links once resolved, as text otherwise.
+//
+// It also parses the ![[image.png]], ![[image.png|300]] and ![[Note#Heading]]
+// embeds, rendered as images and as the embedded content once resolved.
+func New() goldmark.Extender {
+	return &wikiLinkExtension{}
+}
+
+// Extend implements goldmark.Extender.
+func (e *wikiLinkExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithInlineParsers(
+			util.Prioritized(defaultParser, 199),
+		),
+	)
+	m.Renderer().AddOptions(
+		renderer.WithNodeRenderers(
+			util.Prioritized(NewRenderer(), 500),
+		),
+	)
+}
+
+// WikiLink struct represents a link to another note, or an embed.
+type WikiLink struct {
+	ast.BaseInline
+	Note        string // empty when the link targets the current note
+	Heading     string
+	Label       string
+	Embed       bool
+	Width       string // size hint of the embedded images
+	Height      string
+	CardID      string // set once the link is resolved
+	Destination string // set once the embedded image is resolved
+	Content     string // set once the embedded note is resolved
+}
+
+// KindWikiLink is a NodeKind of the WikiLink node.
+var KindWikiLink = ast.NewNodeKind("WikiLink")
+
+// Kind implements Node.Kind.
+func (n *WikiLink) Kind() ast.NodeKind {
+	return KindWikiLink
+}
+
+// Dump implements Node.Dump.
+func (n *WikiLink) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{
+		"Note":    n.Note,
+		"Heading": n.Heading,
+		"Label":   n.Label,
+		"Embed":   fmt.Sprint(n.Embed),
+		"CardID":  n.CardID,
+	}, nil)
+}
+
+// Fallback returns the text the link is rendered as when not resolved.
+func (n *WikiLink) Fallback() string {
+	if n.Label != "" {
+		return n.Label
+	}
+
+	var parts []string
+	for _, part := range []string{n.Note, n.Heading} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
+// NewWikiLink returns a new WikiLink node from the content between the brackets.
+//
+// The label of an embed is its size hint when it matches 300 or 300x200.
+func NewWikiLink(content string, embed bool) *WikiLink {
+	target, label, _ := strings.Cut(content, "|")
+	note, heading, _ := strings.Cut(target, "#")
+	link := &WikiLink{
+		Note:    strings.TrimSpace(note),
+		Heading: strings.TrimSpace(heading),
+		Label:   strings.TrimSpace(label),
+		Embed:   embed,
+	}
+
+	if match := sizeRegexp.FindStringSubmatch(link.Label); embed && match != nil {
+		link.Label, link.Width, link.Height = "", match[1], match[2]
+	}
+
+	return link
+}
+
+type wikiLinkParser struct{}
+
+var (
+	defaultParser = &wikiLinkParser{}
+
+	_ parser.InlineParser = (*wikiLinkParser)(nil)
+)
+
+// Trigger implements parser.InlineParser.
+func (p *wikiLinkParser) Trigger() []byte {
+	return []byte{'!', '['}
+}
+
+// Parse implements parser.InlineParser.
+func (p *wikiLinkParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	embed := bytes.HasPrefix(line, []byte("!"))
+	if embed {
+		line = line[1:]
+	}
+	if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+
+	end := bytes.Index(line, []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+
+	content := line[2:end]
+	if bytes.ContainsAny(content, "[]") || len(bytes.TrimSpace(content)) == 0 {
+		return nil
+	}
+
+	link := NewWikiLink(string(content), embed)
+	if link.Note == "" && link.Heading == "" {
+		return nil
+	}
+
+	if embed {
+		end++
+	}
+	block.Advance(end + 2)
+	return link
+}
+
+// Renderer struct is a renderer.NodeRenderer implementation for the extension.
+type Renderer struct{}
+
+// NewRenderer builds a new Renderer and returns it.
+func NewRenderer() renderer.NodeRenderer {
+	return &Renderer{}
+}
+
+// RegisterFuncs implements NodeRenderer.RegisterFuncs.
+func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindWikiLink, r.renderWikiLink)
+}
+
+func (r *Renderer) renderWikiLink(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	link := node.(*WikiLink)
+	switch {
+	case link.Content != "":
+		_, _ = w.Write([]byte(link.Content))
+	case link.Destination != "" && (link.Width != "" || link.Height != ""):
+		_, _ = w.Write([]byte(imageTag(link)))
+	case link.Destination != "":
+		_, _ = w.Write([]byte(fmt.Sprintf("![%s](%s)", link.Label, link.Destination)))
+	case link.CardID != "":
+		_, _ = w.Write([]byte("[[" + link.CardID + "]]"))
+	default:
+		_, _ = w.Write([]byte(link.Fallback()))
+	}
+
+	return ast.WalkContinue, nil
+}
+
+func imageTag(link *WikiLink) string {
+	attributes := []string{fmt.Sprintf("src=%q", html.EscapeString(link.Destination))}
+	if link.Label != "" {
+		attributes = append(attributes, fmt.Sprintf("alt=%q", html.EscapeString(link.Label)))
+	}
+	if link.Width != "" {
+		attributes = append(attributes, fmt.Sprintf("width=%q", link.Width))
+	}
+	if link.Height != "" {
+		attributes = append(attributes, fmt.Sprintf("height=%q", link.Height))
+	}
+	return fmt.Sprintf("<img %s>", strings.Join(attributes, " "))
+}
@@ -58,6 +58,11 @@ func CleanCards(ctx context.Context, client CleanCardsClient, lf CleanCardsLockf
 	return nil
 }
 
+// CleanListedCards removes any cards from the lockfile that are not among the listed mochi cards.
+func CleanListedCards(lf CleanCardsLockfile, deckID string, mochiCards []mochi.Card) {
+	cleanCards(lf, mochiCards, deckID)
+}
+
 func cleanDecks(lf CleanDecksLockfile, mochiDecks []mochi.Deck) {
 	lf.Lock()
 	defer lf.Unlock()
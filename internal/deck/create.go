@@ -41,7 +41,7 @@ func Create(ctx context.Context, client CreateClient, config CreateConfig, lf Cr
 	defer lf.Unlock()
 
 	id, deck, ok := lf.DeckFromPath(path)
-	if name := getDeckName(config, path); ok && deck.Name != name {
+	if name := Name(config, path); ok && deck.Name != name {
 		err := updateDeckName(ctx, client, lf, id, name)
 		return id, err
 	} else if ok {
@@ -91,7 +91,7 @@ func createDecks(ctx context.Context, client CreateClient, config CreateConfig,
 	parentID, stack := getStack(lf, path)
 	for currentPath := ""; len(stack) > 0; {
 		currentPath, stack = stack[len(stack)-1], stack[:len(stack)-1]
-		name := getDeckName(config, currentPath)
+		name := Name(config, currentPath)
 		deckID, err := createDeck(ctx, client, lf, parentID, currentPath, name)
 		if err != nil {
 			return "", err
@@ -124,7 +124,8 @@ func updateDeckName(ctx context.Context, client CreateClient, lf CreateLockfile,
 
 var titleCaser = cases.Title(language.English)
 
-func getDeckName(config CreateConfig, path string) string {
+// Name returns the name of the deck of a directory.
+func Name(config CreateConfig, path string) string {
 	deck, ok := config.Deck(path)
 	if ok && deck.Name != "" {
 		return deck.Name
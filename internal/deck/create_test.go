@@ -157,7 +157,7 @@ func Test_updateDeckName(t *testing.T) {
 	}
 }
 
-func Test_getDeckName(t *testing.T) {
+func Test_Name(t *testing.T) {
 	tests := []struct {
 		name  string
 		calls []test.ConfigDeck
@@ -193,7 +193,7 @@ func Test_getDeckName(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cfg := test.NewMockConfig(test.Config{Deck: tt.calls})
-			got := getDeckName(cfg, tt.path)
+			got := Name(cfg, tt.path)
 			assert.Equal(t, tt.want, got)
 			cfg.AssertExpectations(t)
 		})
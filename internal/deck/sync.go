@@ -1,9 +1,12 @@
 package deck
 
 import (
+	"maps"
 	"slices"
+	"strings"
 
 	"github.com/leonhfr/mochi/internal/card"
+	"github.com/leonhfr/mochi/internal/config"
 	"github.com/leonhfr/mochi/internal/converter"
 	"github.com/leonhfr/mochi/internal/lock"
 	"github.com/leonhfr/mochi/internal/request"
@@ -15,31 +18,167 @@ type SyncLockfile interface {
 	Lock()
 	Unlock()
 	Card(deckID string, cardID string) (lock.Card, bool)
+	SetCard(deckID, cardID string, card lock.Card) error
+}
+
+// UnchangedLockfile is the interface the lockfile should implement to detect unchanged decks.
+type UnchangedLockfile interface {
+	Lock()
+	Unlock()
+	Deck(id string) (lock.Deck, bool)
 }
 
 // SyncRequests parses the note files and returns the requests
 // required to sync them.
-func SyncRequests(lf SyncLockfile, deckID string, mochiCards []mochi.Card, parsedCards []card.Card) []request.Request {
-	groupedMochiCards, notMatched := groupMochiCardsByFilename(lf, deckID, mochiCards)
+//
+// Cards are first matched by their persistent identifier, then by name
+// within the same file. The deletion policy applies to the cards whose
+// source vanished.
+func SyncRequests(lf SyncLockfile, deckID string, policy config.Deletion, mochiCards []mochi.Card, parsedCards []card.Card) []request.Request {
+	lockCards := getLockCards(lf, deckID, mochiCards)
+	unchanged := make(map[string]card.Card)
+	reqs, mochiCards, parsedCards := identitySyncRequests(lockCards, deckID, mochiCards, parsedCards, unchanged)
+	groupedMochiCards, notMatched := groupMochiCardsByFilename(lockCards, mochiCards)
 	groupedParsedCards := groupParsedCardsByFilename(parsedCards)
 	groupedCards := groupCardsByFilename(groupedMochiCards, groupedParsedCards)
-	reqs := []request.Request{}
 	for _, mochiCard := range notMatched {
-		reqs = append(reqs, request.DeleteCard(mochiCard.ID))
+		reqs = append(reqs, removeRequests(policy, deckID, mochiCard, lock.Card{}, false)...)
 	}
 	for _, group := range groupedCards {
-		groupReqs := upsertSyncRequests(deckID, group.mochi, group.parsed)
+		groupReqs := upsertSyncRequests(deckID, policy, group.mochi, group.parsed, lockCards, unchanged)
 		reqs = append(reqs, groupReqs...)
 	}
+	setCardHashes(lf, deckID, lockCards, unchanged)
 	return reqs
 }
 
+// FilesUnchanged returns whether the source files of a deck
+// are the same as during the last sync.
+func FilesUnchanged(lf UnchangedLockfile, deckID string, files map[string]string) bool {
+	lf.Lock()
+	defer lf.Unlock()
+	deck, ok := lf.Deck(deckID)
+	return ok && len(deck.Files) > 0 && maps.Equal(deck.Files, files)
+}
+
+// Dependencies returns the sorted paths of the files read during the
+// conversion of the cards of a deck at the last sync.
+func Dependencies(lf UnchangedLockfile, deckID string) []string {
+	lf.Lock()
+	defer lf.Unlock()
+	deck, ok := lf.Deck(deckID)
+	if !ok {
+		return nil
+	}
+	var paths []string
+	for path := range deck.Files {
+		if strings.HasPrefix(path, "/") {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// CardsUnchanged returns whether the parsed cards are the same
+// as the cards synced during the last sync, according to their hashes.
+func CardsUnchanged(lf UnchangedLockfile, deckID string, parsedCards []card.Card) bool {
+	lf.Lock()
+	defer lf.Unlock()
+	deck, ok := lf.Deck(deckID)
+	if !ok || len(deck.Cards) != len(parsedCards) {
+		return false
+	}
+	hashes := make(map[string]int)
+	for _, lockCard := range deck.Cards {
+		hashes[lockCard.Hash]++
+	}
+	for _, parsedCard := range parsedCards {
+		hash := parsedCard.Hash()
+		if hashes[hash] == 0 {
+			return false
+		}
+		hashes[hash]--
+	}
+	return true
+}
+
+// setCardHashes records the hashes and names of the cards that did not need any request.
+//
+// The names are missing from the lockfiles written before the links were resolved.
+func setCardHashes(lf SyncLockfile, deckID string, lockCards map[string]lock.Card, unchanged map[string]card.Card) {
+	lf.Lock()
+	defer lf.Unlock()
+	for cardID, parsedCard := range unchanged {
+		lockCard, ok := lockCards[cardID]
+		hash, name := parsedCard.Hash(), parsedCard.Fields["name"]
+		if ok && (lockCard.Hash != hash || lockCard.Name != name) {
+			lockCard.Hash, lockCard.Name = hash, name
+			// the deck exists since the card is tracked
+			_ = lf.SetCard(deckID, cardID, lockCard)
+		}
+	}
+}
+
+// getLockCards returns the lockfile data of the mochi cards, indexed by card id.
+func getLockCards(lf SyncLockfile, deckID string, mochiCards []mochi.Card) map[string]lock.Card {
+	lf.Lock()
+	defer lf.Unlock()
+	lockCards := make(map[string]lock.Card)
+	for _, mochiCard := range mochiCards {
+		if lockCard, ok := lf.Card(deckID, mochiCard.ID); ok {
+			lockCards[mochiCard.ID] = lockCard
+		}
+	}
+	return lockCards
+}
+
+// identitySyncRequests returns the requests for the cards matched by
+// persistent identifier, and the cards that remain unmatched.
+func identitySyncRequests(lockCards map[string]lock.Card, deckID string, mochiCards []mochi.Card, parsedCards []card.Card, unchanged map[string]card.Card) ([]request.Request, []mochi.Card, []card.Card) {
+	identities := getCardIdentities(lockCards, mochiCards)
+	reqs := []request.Request{}
+	matched := make(map[string]bool)
+	var unmatchedParsed []card.Card
+	for _, parsedCard := range parsedCards {
+		mochiCard, ok := identities[parsedCard.ID]
+		if parsedCard.ID == "" || !ok || matched[mochiCard.ID] {
+			unmatchedParsed = append(unmatchedParsed, parsedCard)
+			continue
+		}
+		matched[mochiCard.ID] = true
+		lockCard := lockCards[mochiCard.ID]
+		if !cardEquals(parsedCard, mochiCard, lockCard) {
+			reqs = append(reqs, request.UpdateCard(deckID, mochiCard.ID, parsedCard, mochiCard.Attachments, restored(mochiCard, lockCard)))
+		} else {
+			unchanged[mochiCard.ID] = parsedCard
+		}
+	}
+	var unmatchedMochi []mochi.Card
+	for _, mochiCard := range mochiCards {
+		if !matched[mochiCard.ID] {
+			unmatchedMochi = append(unmatchedMochi, mochiCard)
+		}
+	}
+	return reqs, unmatchedMochi, unmatchedParsed
+}
+
+func getCardIdentities(lockCards map[string]lock.Card, mochiCards []mochi.Card) map[string]mochi.Card {
+	identities := make(map[string]mochi.Card)
+	for _, mochiCard := range mochiCards {
+		if lockCard, ok := lockCards[mochiCard.ID]; ok && lockCard.ID != "" {
+			identities[lockCard.ID] = mochiCard
+		}
+	}
+	return identities
+}
+
 type fileGroup struct {
 	mochi  []mochi.Card
 	parsed []card.Card
 }
 
-func upsertSyncRequests(deckID string, mochiCards []mochi.Card, parsedCards []card.Card) []request.Request {
+func upsertSyncRequests(deckID string, policy config.Deletion, mochiCards []mochi.Card, parsedCards []card.Card, lockCards map[string]lock.Card, unchanged map[string]card.Card) []request.Request {
 	tmp := make([]card.Card, len(parsedCards))
 	copy(tmp, parsedCards)
 
@@ -47,12 +186,17 @@ func upsertSyncRequests(deckID string, mochiCards []mochi.Card, parsedCards []ca
 	for _, mochiCard := range mochiCards {
 		index := slices.IndexFunc(tmp, func(card card.Card) bool { return cardIs(card, mochiCard) })
 		if index < 0 {
-			reqs = append(reqs, request.DeleteCard(mochiCard.ID))
+			reqs = append(reqs, removeRequests(policy, deckID, mochiCard, lockCards[mochiCard.ID], true)...)
 			continue
 		}
 
-		if !cardEquals(tmp[index], mochiCard) {
-			reqs = append(reqs, request.UpdateCard(deckID, mochiCard.ID, tmp[index], mochiCard.Attachments))
+		// cards matched by name that carry a persistent identifier
+		// are updated so that the identifier is recorded in the lockfile
+		lockCard := lockCards[mochiCard.ID]
+		if !cardEquals(tmp[index], mochiCard, lockCard) || tmp[index].ID != "" {
+			reqs = append(reqs, request.UpdateCard(deckID, mochiCard.ID, tmp[index], mochiCard.Attachments, restored(mochiCard, lockCard)))
+		} else {
+			unchanged[mochiCard.ID] = tmp[index]
 		}
 		tmp = sliceRemove(tmp, index)
 	}
@@ -64,6 +208,25 @@ func upsertSyncRequests(deckID string, mochiCards []mochi.Card, parsedCards []ca
 	return reqs
 }
 
+// removeRequests returns the requests that apply the deletion policy
+// to a card whose source vanished.
+func removeRequests(policy config.Deletion, deckID string, mochiCard mochi.Card, lockCard lock.Card, tracked bool) []request.Request {
+	switch {
+	case policy == config.DeletionKeep:
+		return nil
+	case policy == config.DeletionArchive && mochiCard.Archived:
+		return nil
+	case policy == config.DeletionArchive && tracked:
+		return []request.Request{request.ArchiveTrackedCard(deckID, mochiCard, lockCard)}
+	case policy == config.DeletionArchive:
+		return []request.Request{request.ArchiveCard(deckID, mochiCard.ID)}
+	case tracked:
+		return []request.Request{request.DeleteTrackedCard(deckID, mochiCard, lockCard)}
+	default:
+		return []request.Request{request.DeleteCard(deckID, mochiCard.ID)}
+	}
+}
+
 func sliceRemove[T any](s []T, i int) []T {
 	s[i] = s[len(s)-1]
 	return s[:len(s)-1]
@@ -83,14 +246,11 @@ func groupCardsByFilename(mochiCards map[string][]mochi.Card, parsedCards map[st
 	return groups
 }
 
-func groupMochiCardsByFilename(lf SyncLockfile, deckID string, mochiCards []mochi.Card) (map[string][]mochi.Card, []mochi.Card) {
-	lf.Lock()
-	defer lf.Unlock()
-
+func groupMochiCardsByFilename(lockCards map[string]lock.Card, mochiCards []mochi.Card) (map[string][]mochi.Card, []mochi.Card) {
 	matched := make(map[string][]mochi.Card)
 	var notMatched []mochi.Card
 	for _, mochiCard := range mochiCards {
-		if lockCard, ok := lf.Card(deckID, mochiCard.ID); ok {
+		if lockCard, ok := lockCards[mochiCard.ID]; ok {
 			matched[lockCard.Filename] = append(matched[lockCard.Filename], mochiCard)
 		} else {
 			notMatched = append(notMatched, mochiCard)
@@ -112,14 +272,26 @@ func cardIs(card card.Card, mochiCard mochi.Card) bool {
 	return ok && name.Value == card.Fields["name"]
 }
 
-func cardEquals(card card.Card, mochiCard mochi.Card) bool {
-	return mochiCard.Content == card.Content &&
+// cardEquals returns whether the mochi card is up to date.
+//
+// A card archived by hand stays archived, only the cards archived
+// by the deletion policy are restored.
+func cardEquals(card card.Card, mochiCard mochi.Card, lockCard lock.Card) bool {
+	return !restored(mochiCard, lockCard) &&
+		mochiCard.Content == card.Content &&
 		mochiCard.TemplateID == card.TemplateID &&
+		mochiCard.ReviewReverse == card.ReviewReverse &&
 		mochiCard.Pos == card.Position &&
 		mapsEqual(mochiCard.Fields, mochiFields(card.Fields)) &&
 		hasAttachments(card.Attachments, mochiCard.Attachments)
 }
 
+// restored returns whether the card was archived by the deletion policy,
+// and should be un-archived now that its source is back.
+func restored(mochiCard mochi.Card, lockCard lock.Card) bool {
+	return mochiCard.Archived && lockCard.Archived
+}
+
 func mochiFields(fields map[string]string) map[string]mochi.Field {
 	mochiFields := map[string]mochi.Field{}
 	for key, value := range fields {
@@ -128,13 +300,18 @@ func mochiFields(fields map[string]string) map[string]mochi.Field {
 	return mochiFields
 }
 
+// hasAttachments returns whether the mochi card has exactly the attachments.
+//
+// The filenames are derived from the content, so comparing them compares the content.
 func hasAttachments(attachments []converter.Attachment, mochiAttachments map[string]mochi.Attachment) bool {
+	filenames := make(map[string]struct{}, len(attachments))
 	for _, attachment := range attachments {
-		if mochiAttachment, ok := mochiAttachments[attachment.Filename]; !ok || mochiAttachment.Size != len(attachment.Bytes) {
+		if _, ok := mochiAttachments[attachment.Filename]; !ok {
 			return false
 		}
+		filenames[attachment.Filename] = struct{}{}
 	}
-	return true
+	return len(filenames) == len(mochiAttachments)
 }
 
 func mapsEqual[T comparable](m1, m2 map[string]T) bool {
@@ -6,8 +6,12 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/leonhfr/mochi/internal/card"
+	"github.com/leonhfr/mochi/internal/config"
+	"github.com/leonhfr/mochi/internal/converter"
+	"github.com/leonhfr/mochi/internal/lock"
 	"github.com/leonhfr/mochi/internal/parser"
 	"github.com/leonhfr/mochi/internal/request"
+	"github.com/leonhfr/mochi/internal/test"
 	"github.com/leonhfr/mochi/mochi"
 )
 
@@ -34,6 +38,11 @@ func Test_upsertSyncRequests(t *testing.T) {
 			Fields:  map[string]mochi.Field{"name": {ID: "name", Value: "CARD_TO_KEEP"}},
 		},
 	}
+	lockCards := map[string]lock.Card{
+		"CARD_ID_1": {Filename: "lorem-ipsum.md"},
+		"CARD_ID_2": {Filename: "lorem-ipsum.md"},
+		"CARD_ID_3": {Filename: "lorem-ipsum.md"},
+	}
 	parserCards := []card.Card{
 		{
 			Card: parser.Card{
@@ -65,8 +74,8 @@ func Test_upsertSyncRequests(t *testing.T) {
 				Fields:  map[string]string{"name": "CARD_TO_UPDATE"},
 				Path:    path,
 			},
-		}, nil),
-		request.DeleteCard("CARD_ID_2"),
+		}, nil, false),
+		request.DeleteTrackedCard(deckID, mochiCards[1], lockCards["CARD_ID_2"]),
 		request.CreateCard("DECK_ID", card.Card{
 			Card: parser.Card{
 				Content: "CONTENT",
@@ -76,6 +85,331 @@ func Test_upsertSyncRequests(t *testing.T) {
 		}),
 	}
 
-	got := upsertSyncRequests(deckID, mochiCards, parserCards)
+	unchanged := make(map[string]card.Card)
+	got := upsertSyncRequests(deckID, config.DeletionDelete, mochiCards, parserCards, lockCards, unchanged)
+	assert.Equal(t, want, got)
+	assert.Equal(t, map[string]card.Card{"CARD_ID_3": parserCards[2]}, unchanged)
+}
+
+func Test_identitySyncRequests(t *testing.T) {
+	path := "/testdata/lorem-ipsum.md"
+	deckID := "DECK_ID"
+	mochiCards := []mochi.Card{
+		{
+			ID:      "CARD_ID_1",
+			Content: "CONTENT",
+			Fields:  map[string]mochi.Field{"name": {ID: "name", Value: "OLD_NAME"}},
+		},
+		{
+			ID:      "CARD_ID_2",
+			Content: "CONTENT",
+			Fields:  map[string]mochi.Field{"name": {ID: "name", Value: "CARD_TO_KEEP"}},
+		},
+		{
+			ID:      "CARD_ID_3",
+			Content: "CONTENT",
+			Fields:  map[string]mochi.Field{"name": {ID: "name", Value: "CARD_WITHOUT_IDENTITY"}},
+		},
+	}
+	renamed := card.Card{Card: parser.Card{
+		ID:      "IDENTITY_1",
+		Content: "CONTENT",
+		Fields:  map[string]string{"name": "NEW_NAME"},
+		Path:    path,
+	}}
+	kept := card.Card{Card: parser.Card{
+		ID:      "IDENTITY_2",
+		Content: "CONTENT",
+		Fields:  map[string]string{"name": "CARD_TO_KEEP"},
+		Path:    path,
+	}}
+	unknown := card.Card{Card: parser.Card{
+		ID:      "IDENTITY_4",
+		Content: "CONTENT",
+		Fields:  map[string]string{"name": "CARD_WITHOUT_IDENTITY"},
+		Path:    path,
+	}}
+	lockCards := map[string]lock.Card{
+		"CARD_ID_1": {Filename: "lorem-ipsum.md", ID: "IDENTITY_1"},
+		"CARD_ID_2": {Filename: "lorem-ipsum.md", ID: "IDENTITY_2"},
+		"CARD_ID_3": {Filename: "lorem-ipsum.md"},
+	}
+
+	unchanged := make(map[string]card.Card)
+	reqs, gotMochi, gotParsed := identitySyncRequests(lockCards, deckID, mochiCards, []card.Card{renamed, kept, unknown}, unchanged)
+	assert.Equal(t, []request.Request{request.UpdateCard(deckID, "CARD_ID_1", renamed, nil, false)}, reqs)
+	assert.Equal(t, []mochi.Card{mochiCards[2]}, gotMochi)
+	assert.Equal(t, []card.Card{unknown}, gotParsed)
+	assert.Equal(t, map[string]card.Card{"CARD_ID_2": kept}, unchanged)
+}
+
+func Test_getLockCards(t *testing.T) {
+	deckID := "DECK_ID"
+	mochiCards := []mochi.Card{{ID: "CARD_ID_1"}, {ID: "CARD_ID_2"}}
+	lf := test.NewMockLockfile(test.Lockfile{
+		Lock: 1,
+		Card: []test.LockfileCard{
+			{DeckID: deckID, CardID: "CARD_ID_1", Card: lock.Card{Filename: "lorem-ipsum.md", ID: "IDENTITY_1"}, OK: true},
+			{DeckID: deckID, CardID: "CARD_ID_2"},
+		},
+	})
+
+	want := map[string]lock.Card{"CARD_ID_1": {Filename: "lorem-ipsum.md", ID: "IDENTITY_1"}}
+	got := getLockCards(lf, deckID, mochiCards)
 	assert.Equal(t, want, got)
+	lf.AssertExpectations(t)
+}
+
+func Test_removeRequests(t *testing.T) {
+	deckID := "DECK_ID"
+	mochiCard := mochi.Card{ID: "CARD_ID"}
+	archivedCard := mochi.Card{ID: "CARD_ID", Archived: true}
+	lockCard := lock.Card{Filename: "lorem-ipsum.md"}
+
+	tests := []struct {
+		name      string
+		policy    config.Deletion
+		mochiCard mochi.Card
+		tracked   bool
+		want      []request.Request
+	}{
+		{
+			name:      "delete tracked",
+			policy:    config.DeletionDelete,
+			mochiCard: mochiCard,
+			tracked:   true,
+			want:      []request.Request{request.DeleteTrackedCard(deckID, mochiCard, lockCard)},
+		},
+		{
+			name:      "delete untracked",
+			policy:    config.DeletionDelete,
+			mochiCard: mochiCard,
+			want:      []request.Request{request.DeleteCard(deckID, "CARD_ID")},
+		},
+		{
+			name:      "archive tracked",
+			policy:    config.DeletionArchive,
+			mochiCard: mochiCard,
+			tracked:   true,
+			want:      []request.Request{request.ArchiveTrackedCard(deckID, mochiCard, lockCard)},
+		},
+		{
+			name:      "archive untracked",
+			policy:    config.DeletionArchive,
+			mochiCard: mochiCard,
+			want:      []request.Request{request.ArchiveCard(deckID, "CARD_ID")},
+		},
+		{
+			name:      "archive already archived",
+			policy:    config.DeletionArchive,
+			mochiCard: archivedCard,
+			tracked:   true,
+		},
+		{
+			name:      "keep",
+			policy:    config.DeletionKeep,
+			mochiCard: mochiCard,
+			tracked:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := removeRequests(tt.policy, deckID, tt.mochiCard, lockCard, tt.tracked)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_upsertSyncRequests_archived(t *testing.T) {
+	path := "/testdata/lorem-ipsum.md"
+	deckID := "DECK_ID"
+	mochiCards := []mochi.Card{
+		{
+			ID:       "CARD_ID_1",
+			Content:  "CONTENT",
+			Fields:   map[string]mochi.Field{"name": {ID: "name", Value: "CARD"}},
+			Archived: true,
+		},
+	}
+	parsed := card.Card{Card: parser.Card{
+		Content: "CONTENT",
+		Fields:  map[string]string{"name": "CARD"},
+		Path:    path,
+	}}
+
+	tests := []struct {
+		name     string
+		lockCard lock.Card
+		want     []request.Request
+	}{
+		{
+			name:     "archived by the deletion policy",
+			lockCard: lock.Card{Filename: "lorem-ipsum.md", Archived: true},
+			want:     []request.Request{request.UpdateCard(deckID, "CARD_ID_1", parsed, nil, true)},
+		},
+		{
+			name:     "archived by hand",
+			lockCard: lock.Card{Filename: "lorem-ipsum.md"},
+			want:     []request.Request{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lockCards := map[string]lock.Card{"CARD_ID_1": tt.lockCard}
+			got := upsertSyncRequests(deckID, config.DeletionArchive, mochiCards, []card.Card{parsed}, lockCards, map[string]card.Card{})
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_setCardHashes(t *testing.T) {
+	deckID := "DECK_ID"
+	parsed := card.Card{Card: parser.Card{Content: "CONTENT", Fields: map[string]string{"name": "Lorem"}, Path: "/lorem-ipsum.md"}}
+	lockCards := map[string]lock.Card{
+		"CARD_ID_1": {Filename: "lorem-ipsum.md", Name: "Lorem"},
+		"CARD_ID_2": {Filename: "lorem-ipsum.md", Name: "Lorem", Hash: parsed.Hash()},
+		"CARD_ID_3": {Filename: "lorem-ipsum.md", Hash: parsed.Hash()},
+	}
+	unchanged := map[string]card.Card{"CARD_ID_1": parsed, "CARD_ID_2": parsed, "CARD_ID_3": parsed}
+	lf := test.NewMockLockfile(test.Lockfile{
+		Lock: 1,
+		SetCard: []test.LockfileSetCard{
+			{DeckID: deckID, CardID: "CARD_ID_1", Card: lock.Card{Filename: "lorem-ipsum.md", Name: "Lorem", Hash: parsed.Hash()}},
+			{DeckID: deckID, CardID: "CARD_ID_3", Card: lock.Card{Filename: "lorem-ipsum.md", Name: "Lorem", Hash: parsed.Hash()}},
+		},
+	})
+
+	setCardHashes(lf, deckID, lockCards, unchanged)
+	lf.AssertExpectations(t)
+}
+
+func Test_FilesUnchanged(t *testing.T) {
+	files := map[string]string{"lorem-ipsum.md": "HASH"}
+
+	tests := []struct {
+		name  string
+		deck  test.LockfileDeck
+		files map[string]string
+		want  bool
+	}{
+		{
+			name:  "deck not found",
+			deck:  test.LockfileDeck{DeckID: "DECK_ID"},
+			files: files,
+		},
+		{
+			name:  "no previous files",
+			deck:  test.LockfileDeck{DeckID: "DECK_ID", Deck: lock.Deck{}, OK: true},
+			files: files,
+		},
+		{
+			name:  "changed files",
+			deck:  test.LockfileDeck{DeckID: "DECK_ID", Deck: lock.Deck{Files: files}, OK: true},
+			files: map[string]string{"lorem-ipsum.md": "OTHER"},
+		},
+		{
+			name:  "unchanged files",
+			deck:  test.LockfileDeck{DeckID: "DECK_ID", Deck: lock.Deck{Files: files}, OK: true},
+			files: map[string]string{"lorem-ipsum.md": "HASH"},
+			want:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := test.NewMockLockfile(test.Lockfile{Lock: 1, Deck: []test.LockfileDeck{tt.deck}})
+			got := FilesUnchanged(lf, "DECK_ID", tt.files)
+			assert.Equal(t, tt.want, got)
+			lf.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Dependencies(t *testing.T) {
+	deck := lock.Deck{Files: map[string]string{
+		"lorem-ipsum.md":  "HASH",
+		"/images/cat.png": "HASH",
+		"/audio/word.mp3": "",
+	}}
+	lf := test.NewMockLockfile(test.Lockfile{Lock: 1, Deck: []test.LockfileDeck{{DeckID: "DECK_ID", Deck: deck, OK: true}}})
+	got := Dependencies(lf, "DECK_ID")
+	assert.Equal(t, []string{"/audio/word.mp3", "/images/cat.png"}, got)
+	lf.AssertExpectations(t)
+}
+
+func Test_CardsUnchanged(t *testing.T) {
+	first := card.Card{Card: parser.Card{Content: "FIRST", Path: "/lorem-ipsum.md"}}
+	second := card.Card{Card: parser.Card{Content: "SECOND", Path: "/lorem-ipsum.md"}}
+	deck := lock.Deck{Cards: map[string]lock.Card{
+		"CARD_ID_1": {Filename: "lorem-ipsum.md", Hash: first.Hash()},
+		"CARD_ID_2": {Filename: "lorem-ipsum.md", Hash: second.Hash()},
+	}}
+
+	tests := []struct {
+		name  string
+		cards []card.Card
+		want  bool
+	}{
+		{
+			name:  "same cards",
+			cards: []card.Card{second, first},
+			want:  true,
+		},
+		{
+			name:  "missing card",
+			cards: []card.Card{first},
+		},
+		{
+			name:  "changed card",
+			cards: []card.Card{first, first},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := test.NewMockLockfile(test.Lockfile{
+				Lock: 1,
+				Deck: []test.LockfileDeck{{DeckID: "DECK_ID", Deck: deck, OK: true}},
+			})
+			got := CardsUnchanged(lf, "DECK_ID", tt.cards)
+			assert.Equal(t, tt.want, got)
+			lf.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_hasAttachments(t *testing.T) {
+	tests := []struct {
+		name             string
+		attachments      []converter.Attachment
+		mochiAttachments map[string]mochi.Attachment
+		want             bool
+	}{
+		{
+			name:             "same attachments",
+			attachments:      []converter.Attachment{{Filename: "a.png"}, {Filename: "a.png"}, {Filename: "b.mp3"}},
+			mochiAttachments: map[string]mochi.Attachment{"a.png": {Size: 1}, "b.mp3": {Size: 1}},
+			want:             true,
+		},
+		{
+			name:             "missing attachment",
+			attachments:      []converter.Attachment{{Filename: "a.png"}, {Filename: "c.png"}},
+			mochiAttachments: map[string]mochi.Attachment{"a.png": {Size: 1}},
+			want:             false,
+		},
+		{
+			name:             "stale attachment",
+			attachments:      []converter.Attachment{{Filename: "a.png"}},
+			mochiAttachments: map[string]mochi.Attachment{"a.png": {Size: 1}, "b.png": {Size: 1}},
+			want:             false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hasAttachments(tt.attachments, tt.mochiAttachments))
+		})
+	}
 }
@@ -1,6 +1,7 @@
 package file
 
 import (
+	"errors"
 	"io"
 	"io/fs"
 	"os"
@@ -59,7 +60,58 @@ func (System) Read(path string) (io.ReadCloser, error) {
 }
 
 // Write returns an io.WriteCloser to the file at path.
+//
+// Missing parent directories are created. The content is written to a
+// temporary file in the same directory, renamed over the file on Close,
+// so that the file is never left half written.
 func (System) Write(path string) (io.WriteCloser, error) {
-	file, err := os.Create(path)
-	return file, err
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &atomicFile{tmp: tmp, path: path}, nil
+}
+
+// atomicFile replaces the file at path with the temporary file on Close.
+type atomicFile struct {
+	tmp  *os.File
+	path string
+	err  error // first write error, the file is left untouched if set
+}
+
+// Write implements the io.Writer interface.
+func (f *atomicFile) Write(p []byte) (int, error) {
+	n, err := f.tmp.Write(p)
+	if err != nil && f.err == nil {
+		f.err = err
+	}
+	return n, err
+}
+
+// Close implements the io.Closer interface.
+func (f *atomicFile) Close() error {
+	err := errors.Join(f.err, f.tmp.Close())
+	if err == nil {
+		err = os.Chmod(f.tmp.Name(), fileMode(f.path))
+	}
+	if err == nil {
+		err = os.Rename(f.tmp.Name(), f.path)
+	}
+	if err != nil {
+		_ = os.Remove(f.tmp.Name())
+	}
+	return err
+}
+
+// fileMode returns the permissions of the file at path,
+// or the default permissions if it does not exist.
+func fileMode(path string) fs.FileMode {
+	if info, err := os.Stat(path); err == nil {
+		return info.Mode().Perm()
+	}
+	return 0o644
 }
@@ -2,9 +2,12 @@ package file
 
 import (
 	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_Walk(t *testing.T) {
@@ -42,3 +45,33 @@ func Test_Open_Error(t *testing.T) {
 		})
 	}
 }
+
+func Test_Write(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes", "note.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte("old content"), 0o600))
+
+	wc, err := NewSystem().Write(path)
+	require.NoError(t, err)
+	_, err = wc.Write([]byte("new"))
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old content", string(got), "not replaced before Close")
+
+	require.NoError(t, wc.Close())
+
+	got, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(got))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary file removed")
+}
@@ -24,6 +24,16 @@ func (h *Heap[T]) Push(item T) {
 	heap.Push(h.heap, item)
 }
 
+// PushEmpty pushes an empty group, unless a group with the same base exists.
+func (h *Heap[T]) PushEmpty(base string, priority int) {
+	for _, group := range *h.heap {
+		if group.Base == base {
+			return
+		}
+	}
+	heap.Push(h.heap, Group[T]{Base: base, priority: priority})
+}
+
 // Pop returns the heap item with the most priority (lowest).
 func (h *Heap[T]) Pop() Group[T] {
 	return heap.Pop(h.heap).(Group[T])
@@ -68,6 +78,11 @@ func (h priorityHeap[T]) Len() int      { return len(h) }           // Len imple
 
 // Push implements heap.Interface.
 func (h *priorityHeap[T]) Push(x any) {
+	if group, ok := x.(Group[T]); ok {
+		*h = append(*h, group)
+		return
+	}
+
 	newItem := x.(T)
 	for i, item := range *h {
 		if item.Base == newItem.Base() {
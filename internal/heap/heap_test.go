@@ -34,3 +34,19 @@ func Test_Heap_Path(t *testing.T) {
 
 	assert.Equal(t, want, got)
 }
+
+func Test_Heap_PushEmpty(t *testing.T) {
+	want := []Group[Path]{
+		{priority: 1, Base: "/a", Items: []Path{"/a/note.md"}},
+		{priority: 1, Base: "/b"},
+		{priority: 2, Base: "/b/c"},
+	}
+
+	h := New[Path]()
+	h.Push("/a/note.md")
+	h.PushEmpty("/b/c", DirPriority("/b/c"))
+	h.PushEmpty("/a", DirPriority("/a"))
+	h.PushEmpty("/b", DirPriority("/b"))
+
+	assert.Equal(t, want, h.Drain())
+}
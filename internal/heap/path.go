@@ -17,11 +17,15 @@ func (p Path) Base() string {
 
 // Priority implements the PriorityItem interface.
 func (p Path) Priority() int {
-	base := p.Base()
-	if base == "/" {
+	return DirPriority(p.Base())
+}
+
+// DirPriority returns the priority of the paths of a directory.
+func DirPriority(dir string) int {
+	if dir == "/" {
 		return 0
 	}
-	return strings.Count(base, "/")
+	return strings.Count(dir, "/")
 }
 
 // ConvertPaths converts a slice of paths back to string.
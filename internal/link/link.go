@@ -0,0 +1,240 @@
+// Package link resolves the links between notes to the synced cards.
+package link
+
+import (
+	"cmp"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/leonhfr/mochi/internal/lock"
+)
+
+const headingSeparator = " > "
+
+// clozeRegexp matches the suffix of the names of the cloze and highlight cards.
+var clozeRegexp = regexp.MustCompile(` \((?:c\d+|h)\)$`)
+
+// Lockfile is the interface the lockfile should implement to index the cards.
+type Lockfile interface {
+	Lock()
+	Unlock()
+	Decks() map[string]lock.Deck
+}
+
+// Logger is the interface to log output.
+type Logger interface {
+	Infof(format string, args ...any)
+}
+
+// Index represents the index of the cards of the workspace.
+//
+// It is built from the lockfile, then completed with the cards parsed
+// from the workspace before their conversion. The cards not synced yet
+// have no ID, the links to them are only resolved on the next sync.
+type Index struct {
+	workspace string
+	cards     []indexCard // sorted by directory, filename, name and card id
+	keys      map[indexKey]struct{}
+	cardIDs   map[string]struct{}
+	logger    Logger
+}
+
+type indexCard struct {
+	dir    string // directory of the source file, relative to the workspace
+	note   string // lowercase filename without extension
+	name   string // lowercase card name, without the cloze suffix
+	cardID string // empty when the card is not synced yet
+}
+
+// indexKey identifies the cards of a note by name.
+type indexKey struct {
+	dir  string
+	note string
+	name string
+}
+
+// New returns the index of the cards of the lockfile.
+func New(lf Lockfile, workspace string, logger Logger) *Index {
+	lf.Lock()
+	defer lf.Unlock()
+
+	decks := lf.Decks()
+	index := &Index{
+		workspace: workspace,
+		keys:      map[indexKey]struct{}{},
+		cardIDs:   map[string]struct{}{},
+		logger:    logger,
+	}
+	for _, deck := range decks {
+		dir := deck.Path
+		if parent, ok := decks[deck.ParentID]; ok && deck.Virtual {
+			dir = parent.Path
+		}
+
+		for cardID, card := range deck.Cards {
+			card := newIndexCard(dir, card.Filename, card.Name, cardID)
+			index.cardIDs[cardID] = struct{}{}
+			index.keys[card.key()] = struct{}{}
+			index.cards = append(index.cards, card)
+		}
+	}
+
+	slices.SortFunc(index.cards, compareIndexCards)
+	return index
+}
+
+// Add indexes the names of the cards parsed from the note at path.
+//
+// The cards already synced keep their ID, the others are indexed without ID.
+func (i *Index) Add(path string, names []string) {
+	dir := getRelativeDir(i.workspace, path)
+	for _, name := range names {
+		card := newIndexCard(dir, filepath.Base(path), name, "")
+		if _, ok := i.keys[card.key()]; ok {
+			continue
+		}
+
+		i.keys[card.key()] = struct{}{}
+		index, _ := slices.BinarySearchFunc(i.cards, card, compareIndexCards)
+		i.cards = slices.Insert(i.cards, index, card)
+	}
+}
+
+func newIndexCard(dir, filename, name, cardID string) indexCard {
+	return indexCard{
+		dir:    dir,
+		note:   strings.ToLower(getNameFromPath(filename)),
+		name:   strings.ToLower(clozeRegexp.ReplaceAllString(name, "")),
+		cardID: cardID,
+	}
+}
+
+func (c indexCard) key() indexKey {
+	return indexKey{dir: c.dir, note: c.note, name: c.name}
+}
+
+func compareIndexCards(a, b indexCard) int {
+	return cmp.Or(
+		strings.Compare(a.dir, b.dir),
+		strings.Compare(a.note, b.note),
+		strings.Compare(a.name, b.name),
+		strings.Compare(a.cardID, b.cardID),
+	)
+}
+
+// Resolve returns the ID of the card a link targets from the note at path.
+//
+// The note may be empty to target the current note, or contain directories
+// to disambiguate notes sharing a name. Notes in the same directory as the
+// current note are preferred. Without heading, the card named after the note
+// is preferred, or the card with the fewest headings otherwise.
+// Links to card IDs are kept as they are. The ID is empty when
+// the card is not synced yet.
+//
+// Implements the converter.Resolver interface.
+func (i *Index) Resolve(path, note, heading string) (string, bool) {
+	dir := getRelativeDir(i.workspace, path)
+	if note == "" {
+		note = getNameFromPath(path)
+	}
+
+	target := note
+	if heading != "" {
+		target += "#" + heading
+	}
+
+	if cardID, ok := i.resolve(dir, note, heading); ok {
+		if cardID == "" {
+			i.logger.Infof("link(%s): [[%s]] targets a card not synced yet, converted to text until the next sync", path, target)
+		}
+		return cardID, true
+	}
+
+	if _, ok := i.cardIDs[note]; ok && heading == "" {
+		return note, true
+	}
+
+	i.logger.Infof("link(%s): warning: [[%s]] does not match any card, converted to text", path, target)
+	return "", false
+}
+
+func (i *Index) resolve(dir, note, heading string) (string, bool) {
+	noteDir, noteName := filepath.Split(filepath.ToSlash(note))
+	noteName = strings.ToLower(strings.TrimSuffix(noteName, ".md"))
+	noteDir = strings.Trim(noteDir, "/")
+	heading = strings.ToLower(heading)
+
+	var candidates []indexCard
+	for _, card := range i.cards {
+		if card.note != noteName || !hasDirSuffix(card.dir, noteDir) {
+			continue
+		}
+		if heading != "" && !hasHeading(card.name, heading) {
+			continue
+		}
+		candidates = append(candidates, card)
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	sameDir := slices.DeleteFunc(slices.Clone(candidates), func(card indexCard) bool {
+		return card.dir != dir
+	})
+	if len(sameDir) > 0 {
+		candidates = sameDir
+	}
+
+	best := candidates[0]
+	for _, card := range candidates[1:] {
+		if betterMatch(card, best, noteName) {
+			best = card
+		}
+	}
+	return best.cardID, true
+}
+
+// betterMatch returns whether a is a better match than b for a link without heading.
+//
+// The synced cards are preferred over the cards not synced yet.
+func betterMatch(a, b indexCard, note string) bool {
+	if (a.name == note) != (b.name == note) {
+		return a.name == note
+	}
+	if headingsA, headingsB := strings.Count(a.name, headingSeparator), strings.Count(b.name, headingSeparator); headingsA != headingsB {
+		return headingsA < headingsB
+	}
+	return a.cardID != "" && b.cardID == ""
+}
+
+// hasHeading returns whether the last heading of the card name matches the heading.
+func hasHeading(name, heading string) bool {
+	if index := strings.LastIndex(name, headingSeparator); index >= 0 {
+		name = name[index+len(headingSeparator):]
+	}
+	return strings.TrimSpace(name) == strings.TrimSpace(heading)
+}
+
+// hasDirSuffix returns whether the directory ends with the relative directory.
+func hasDirSuffix(dir, suffix string) bool {
+	if suffix == "" {
+		return true
+	}
+	return strings.HasSuffix(strings.ToLower(dir)+"/", "/"+strings.ToLower(suffix)+"/")
+}
+
+func getRelativeDir(workspace, path string) string {
+	rel, err := filepath.Rel(workspace, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return "/"
+	}
+	return filepath.Join("/", rel)
+}
+
+func getNameFromPath(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
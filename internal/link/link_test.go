@@ -0,0 +1,167 @@
+package link
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/internal/test"
+)
+
+func Test_Index_Resolve(t *testing.T) {
+	decks := map[string]lock.Deck{
+		"DECK_1": {Path: "/notes", Name: "Notes", Cards: map[string]lock.Card{
+			"CARD_1": {Filename: "Cooking.md", Name: "Cooking"},
+			"CARD_2": {Filename: "Physics.md", Name: "Physics > Mechanics"},
+			"CARD_3": {Filename: "Physics.md", Name: "Physics > Mechanics > Newton's laws"},
+			"CARD_4": {Filename: "Physics.md", Name: "Physics > Optics"},
+			"CARD_7": {Filename: "Capitals.md", Name: "Capitals > Europe (c1)"},
+		}},
+		"DECK_2": {Path: "/archive", Name: "Archive", Cards: map[string]lock.Card{
+			"CARD_5": {Filename: "Cooking.md", Name: "Cooking"},
+		}},
+		"DECK_3": {ParentID: "DECK_2", Name: "Virtual", Virtual: true, Cards: map[string]lock.Card{
+			"CARD_6": {Filename: "Glossary.md", Name: "Term"},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		note    string
+		heading string
+		want    string
+		ok      bool
+	}{
+		{
+			name: "note",
+			path: "/workspace/notes/Index.md",
+			note: "Physics",
+			want: "CARD_2",
+			ok:   true,
+		},
+		{
+			name:    "heading",
+			path:    "/workspace/notes/Index.md",
+			note:    "physics",
+			heading: "Newton's laws",
+			want:    "CARD_3",
+			ok:      true,
+		},
+		{
+			name:    "current note",
+			path:    "/workspace/notes/Physics.md",
+			heading: "Optics",
+			want:    "CARD_4",
+			ok:      true,
+		},
+		{
+			name: "same directory",
+			path: "/workspace/archive/Index.md",
+			note: "Cooking",
+			want: "CARD_5",
+			ok:   true,
+		},
+		{
+			name: "directory",
+			path: "/workspace/Index.md",
+			note: "notes/Cooking.md",
+			want: "CARD_1",
+			ok:   true,
+		},
+		{
+			name: "virtual deck",
+			path: "/workspace/notes/Index.md",
+			note: "Glossary",
+			want: "CARD_6",
+			ok:   true,
+		},
+		{
+			name: "card id",
+			path: "/workspace/notes/Index.md",
+			note: "CARD_4",
+			want: "CARD_4",
+			ok:   true,
+		},
+		{
+			name:    "cloze",
+			path:    "/workspace/notes/Index.md",
+			note:    "Capitals",
+			heading: "Europe",
+			want:    "CARD_7",
+			ok:      true,
+		},
+		{
+			name:    "added heading",
+			path:    "/workspace/notes/Index.md",
+			note:    "Physics",
+			heading: "Relativity",
+			ok:      true,
+		},
+		{
+			name: "added note",
+			path: "/workspace/notes/Index.md",
+			note: "Chemistry",
+			ok:   true,
+		},
+		{
+			name: "synced card preferred",
+			path: "/workspace/notes/Index.md",
+			note: "Cooking",
+			want: "CARD_1",
+			ok:   true,
+		},
+		{
+			name:    "unknown heading",
+			path:    "/workspace/notes/Index.md",
+			note:    "Physics",
+			heading: "Thermodynamics",
+		},
+		{
+			name: "unknown note",
+			path: "/workspace/notes/Index.md",
+			note: "Biology",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := test.NewMockLockfile(test.Lockfile{Lock: 1, Decks: []map[string]lock.Deck{decks}})
+			index := New(lf, "/workspace", testLogger{})
+			index.Add("/workspace/notes/Physics.md", []string{"Physics > Mechanics", "Physics > Relativity"})
+			index.Add("/workspace/notes/Chemistry.md", []string{"Chemistry"})
+			index.Add("/workspace/notes/Cooking.md", []string{"Cooking > Recipes"})
+			got, ok := index.Resolve(tt.path, tt.note, tt.heading)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.ok, ok)
+			lf.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Index_Add(t *testing.T) {
+	decks := map[string]lock.Deck{
+		"DECK_1": {Path: "/notes", Name: "Notes", Cards: map[string]lock.Card{
+			"CARD_1": {Filename: "Physics.md", Name: "Physics > Optics"},
+		}},
+	}
+
+	lf := test.NewMockLockfile(test.Lockfile{Lock: 1, Decks: []map[string]lock.Deck{decks}})
+	index := New(lf, "/workspace", testLogger{})
+	index.Add("/workspace/notes/Physics.md", []string{"Physics > Optics", "Physics > Mechanics"})
+	index.Add("/workspace/notes/Chemistry.md", []string{"Chemistry (c2)", "Chemistry (c1)", "Chemistry (h)"})
+	index.Add("/workspace/Index.md", []string{"Index"})
+
+	assert.Equal(t, []indexCard{
+		{dir: "/", note: "index", name: "index"},
+		{dir: "/notes", note: "chemistry", name: "chemistry"},
+		{dir: "/notes", note: "physics", name: "physics > mechanics"},
+		{dir: "/notes", note: "physics", name: "physics > optics", cardID: "CARD_1"},
+	}, index.cards)
+	lf.AssertExpectations(t)
+}
+
+type testLogger struct{}
+
+func (testLogger) Infof(string, ...any) {}
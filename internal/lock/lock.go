@@ -5,13 +5,15 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
 	"path/filepath"
 	"sync"
 
 	"github.com/go-playground/validator/v10"
 )
 
-const lockName = "mochi-lock.json"
+// Filename is the name of the lockfile.
+const Filename = "mochi-lock.json"
 
 var validate *validator.Validate
 
@@ -41,16 +43,21 @@ type Lock struct {
 
 // Deck contains the information about existing decks.
 type Deck struct {
-	ParentID string          `json:"parentID,omitempty"`
-	Path     string          `json:"path,omitempty"`
-	Name     string          `json:"name" validate:"required"`
-	Cards    map[string]Card `json:"cards,omitempty" validate:"dive"` // indexed by card id
-	Virtual  bool            `json:"virtual,omitempty"`
+	ParentID string            `json:"parentID,omitempty"`
+	Path     string            `json:"path,omitempty"`
+	Name     string            `json:"name" validate:"required"`
+	Cards    map[string]Card   `json:"cards,omitempty" validate:"dive"` // indexed by card id
+	Files    map[string]string `json:"files,omitempty"`                 // source file hashes indexed by filename, and hashes of the files read during their conversion indexed by path relative to the workspace
+	Virtual  bool              `json:"virtual,omitempty"`
 }
 
 // Card contains the information about existing cards.
 type Card struct {
 	Filename string `json:"filename" validate:"required"` // filename inside directory: note.md
+	ID       string `json:"id,omitempty"`                 // persistent identifier embedded in the source
+	Name     string `json:"name,omitempty"`               // card name, used to resolve the links between notes
+	Hash     string `json:"hash,omitempty"`               // hash of the converted card
+	Archived bool   `json:"archived,omitempty"`           // archived by the deletion policy
 }
 
 // ReaderWriter represents the interface to interact with a lockfile.
@@ -59,14 +66,19 @@ type ReaderWriter interface {
 	Write(string) (io.WriteCloser, error)
 }
 
-// Parse parses the lockfile in the target directory.
-func Parse(rw ReaderWriter, target string) (*Lock, error) {
-	path := filepath.Join(target, lockName)
-	lock := &Lock{
+// New returns an empty lockfile in the target directory.
+func New(rw ReaderWriter, target string) *Lock {
+	return &Lock{
 		decks: make(map[string]Deck),
-		path:  path,
+		path:  filepath.Join(target, Filename),
 		rw:    rw,
 	}
+}
+
+// Parse parses the lockfile in the target directory.
+func Parse(rw ReaderWriter, target string) (*Lock, error) {
+	lock := New(rw, target)
+	path := lock.path
 
 	r, err := rw.Read(path)
 	if err == fs.ErrNotExist {
@@ -195,22 +207,18 @@ func (l *Lock) Card(deckID, cardID string) (Card, bool) {
 // SetCard sets a card in the given deck.
 //
 // Assumes mutex is already acquired.
-func (l *Lock) SetCard(deckID, cardID, filename string) error {
+func (l *Lock) SetCard(deckID, cardID string, card Card) error {
 	if _, ok := l.decks[deckID]; !ok {
 		return fmt.Errorf("deck %s not found", deckID)
 	}
 
 	if l.decks[deckID].Cards == nil {
-		l.decks[deckID] = Deck{
-			Path:  l.decks[deckID].Path,
-			Name:  l.decks[deckID].Name,
-			Cards: map[string]Card{},
-		}
+		deck := l.decks[deckID]
+		deck.Cards = map[string]Card{}
+		l.decks[deckID] = deck
 	}
 
-	l.decks[deckID].Cards[cardID] = Card{
-		Filename: filename,
-	}
+	l.decks[deckID].Cards[cardID] = card
 	l.updated = true
 
 	return nil
@@ -224,6 +232,40 @@ func (l *Lock) DeleteCard(deckID, cardID string) {
 	l.updated = true
 }
 
+// SetFiles sets the source file hashes of a deck.
+//
+// Assumes mutex is already acquired.
+func (l *Lock) SetFiles(deckID string, files map[string]string) {
+	deck, ok := l.decks[deckID]
+	if !ok || maps.Equal(deck.Files, files) {
+		return
+	}
+
+	deck.Files = files
+	l.decks[deckID] = deck
+	l.updated = true
+}
+
+// ResetFiles removes the source file hashes of the directory deck
+// a deck belongs to, so that the directory is synced on the next run.
+//
+// Assumes mutex is already acquired.
+func (l *Lock) ResetFiles(deckID string) {
+	deck, ok := l.decks[deckID]
+	if ok && deck.Virtual {
+		deckID = deck.ParentID
+		deck, ok = l.decks[deckID]
+	}
+
+	if !ok || deck.Files == nil {
+		return
+	}
+
+	deck.Files = nil
+	l.decks[deckID] = deck
+	l.updated = true
+}
+
 // Updated returns whether the lockfile has been updated.
 func (l *Lock) Updated() bool {
 	return l.updated
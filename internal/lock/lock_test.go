@@ -193,43 +193,58 @@ func Test_Lock_Card(t *testing.T) {
 
 func Test_Lock_SetCard(t *testing.T) {
 	tests := []struct {
-		name     string
-		data     map[string]Deck
-		deckID   string
-		cardID   string
-		filename string
-		want     map[string]Deck
-		err      bool
+		name   string
+		data   map[string]Deck
+		deckID string
+		cardID string
+		card   Card
+		want   map[string]Deck
+		err    bool
 	}{
 		{
-			name:     "deck does not exist",
-			data:     map[string]Deck{},
-			deckID:   "DECK_ID",
-			cardID:   "CARD_ID",
-			filename: "/lorem-ipsum.md",
-			want:     map[string]Deck{},
-			err:      true,
+			name:   "deck does not exist",
+			data:   map[string]Deck{},
+			deckID: "DECK_ID",
+			cardID: "CARD_ID",
+			card:   Card{Filename: "/lorem-ipsum.md"},
+			want:   map[string]Deck{},
+			err:    true,
 		},
 		{
 			name: "rewrite when card already exists",
 			data: map[string]Deck{"DECK_ID": {
 				Cards: map[string]Card{"CARD_ID": {Filename: "/old.md"}},
 			}},
-			deckID:   "DECK_ID",
-			cardID:   "CARD_ID",
-			filename: "/lorem-ipsum.md",
+			deckID: "DECK_ID",
+			cardID: "CARD_ID",
+			card:   Card{Filename: "/lorem-ipsum.md"},
 			want: map[string]Deck{"DECK_ID": {
 				Cards: map[string]Card{"CARD_ID": {Filename: "/lorem-ipsum.md"}},
 			}},
 		},
+		{
+			name: "card set with id",
+			data: map[string]Deck{"DECK_ID": {
+				Cards: map[string]Card{},
+			}},
+			deckID: "DECK_ID",
+			cardID: "CARD_ID",
+			card:   Card{Filename: "/lorem-ipsum.md", ID: "CARD_IDENTITY"},
+			want: map[string]Deck{"DECK_ID": {
+				Cards: map[string]Card{"CARD_ID": {
+					Filename: "/lorem-ipsum.md",
+					ID:       "CARD_IDENTITY",
+				}},
+			}},
+		},
 		{
 			name: "card set",
 			data: map[string]Deck{"DECK_ID": {
 				Cards: map[string]Card{},
 			}},
-			deckID:   "DECK_ID",
-			cardID:   "CARD_ID",
-			filename: "/lorem-ipsum.md",
+			deckID: "DECK_ID",
+			cardID: "CARD_ID",
+			card:   Card{Filename: "/lorem-ipsum.md"},
 			want: map[string]Deck{"DECK_ID": {
 				Cards: map[string]Card{"CARD_ID": {
 					Filename: "/lorem-ipsum.md",
@@ -241,7 +256,7 @@ func Test_Lock_SetCard(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			lock := &Lock{decks: tt.data}
-			err := lock.SetCard(tt.deckID, tt.cardID, tt.filename)
+			err := lock.SetCard(tt.deckID, tt.cardID, tt.card)
 			assert.Equal(t, tt.want, lock.decks)
 			if tt.err {
 				assert.Error(t, err)
@@ -273,3 +288,67 @@ type writeCloser struct {
 }
 
 func (writeCloser) Close() error { return nil }
+
+func Test_Lock_SetFiles(t *testing.T) {
+	files := map[string]string{"lorem-ipsum.md": "HASH"}
+	lock := &Lock{decks: map[string]Deck{"DECK_ID": {Path: "/lorem-ipsum"}}}
+
+	lock.SetFiles("UNKNOWN_ID", files)
+	assert.False(t, lock.updated)
+
+	lock.SetFiles("DECK_ID", files)
+	assert.True(t, lock.updated)
+	assert.Equal(t, map[string]Deck{"DECK_ID": {Path: "/lorem-ipsum", Files: files}}, lock.decks)
+
+	lock.updated = false
+	lock.SetFiles("DECK_ID", map[string]string{"lorem-ipsum.md": "HASH"})
+	assert.False(t, lock.updated)
+}
+
+func Test_Lock_ResetFiles(t *testing.T) {
+	files := map[string]string{"lorem-ipsum.md": "HASH"}
+
+	tests := []struct {
+		name    string
+		data    map[string]Deck
+		deckID  string
+		want    map[string]Deck
+		updated bool
+	}{
+		{
+			name:   "deck does not exist",
+			data:   map[string]Deck{},
+			deckID: "DECK_ID",
+			want:   map[string]Deck{},
+		},
+		{
+			name:    "directory deck",
+			data:    map[string]Deck{"DECK_ID": {Path: "/lorem-ipsum", Files: files}},
+			deckID:  "DECK_ID",
+			want:    map[string]Deck{"DECK_ID": {Path: "/lorem-ipsum"}},
+			updated: true,
+		},
+		{
+			name: "virtual deck",
+			data: map[string]Deck{
+				"DECK_ID":    {Path: "/lorem-ipsum", Files: files},
+				"VIRTUAL_ID": {ParentID: "DECK_ID", Name: "Virtual", Virtual: true},
+			},
+			deckID: "VIRTUAL_ID",
+			want: map[string]Deck{
+				"DECK_ID":    {Path: "/lorem-ipsum"},
+				"VIRTUAL_ID": {ParentID: "DECK_ID", Name: "Virtual", Virtual: true},
+			},
+			updated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock := &Lock{decks: tt.data}
+			lock.ResetFiles(tt.deckID)
+			assert.Equal(t, tt.want, lock.decks)
+			assert.Equal(t, tt.updated, lock.updated)
+		})
+	}
+}
@@ -0,0 +1,280 @@
+// Package asciidoc converts AsciiDoc documents to markdown.
+package asciidoc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	attributeRegexp  = regexp.MustCompile(`^:([\w-]+):\s*(.*)$`)
+	headingRegexp    = regexp.MustCompile(`^(=+)\s+(.*?)\s*$`)
+	cardIDRegexp     = regexp.MustCompile(`^//\s*mochi-id:\s*([A-Za-z0-9_-]+)\s*$`)
+	blockAttrsRegexp = regexp.MustCompile(`^\[(\[[^\]]*\]|[^\[\]]*)\]$`)
+	blockTitleRegexp = regexp.MustCompile(`^\.([^\s.].*)$`)
+	imageRegexp      = regexp.MustCompile(`^image::([^\s\[]+)\[([^\]]*)\]$`)
+	listRegexp       = regexp.MustCompile(`^(\*+|-|\.+)\s+(.*)$`)
+	codeRegexp       = regexp.MustCompile("`[^`]+`")
+	inlineImgRegexp  = regexp.MustCompile(`image:([^\s\[:][^\s\[]*)\[([^\]]*)\]`)
+	linkRegexp       = regexp.MustCompile(`(?:link:([^\s\[]+)|((?:https?|ftp|irc|mailto):[^\s\[]+))\[([^\]]*)\]`)
+	strongRegexp     = regexp.MustCompile(`(^|[\s(\[{'"])\*([^\s*](?:[^*]*[^\s*])?)\*($|[\s\-.,:;!?'")\]}])`)
+	emphasisRegexp   = regexp.MustCompile(`(^|[\s(\[{'"])_([^\s_](?:[^_]*[^\s_])?)_($|[\s\-.,:;!?'")\]}])`)
+	unconstrained    = regexp.MustCompile(`__([^_]+)__`)
+)
+
+// Document represents a converted AsciiDoc document.
+type Document struct {
+	Attributes map[string]string // lowercase keys, e.g. map[title]Title
+	Markdown   []byte
+}
+
+// Convert converts an AsciiDoc document to markdown.
+//
+// Section titles, lists, tables, delimited blocks, images, links and
+// inline markup are converted. The document title and the :name: value
+// attribute entries are extracted and removed from the content, as are
+// the comments except the mochi-id markers.
+func Convert(source []byte) Document {
+	c := converter{attributes: map[string]string{}}
+	for _, line := range strings.Split(string(source), "\n") {
+		c.line(line)
+	}
+
+	markdown := strings.TrimLeft(strings.Join(c.lines, "\n"), "\n")
+	return Document{
+		Attributes: c.attributes,
+		Markdown:   []byte(markdown),
+	}
+}
+
+type converter struct {
+	attributes map[string]string
+	lines      []string
+	style      string   // style of the next block, e.g. source
+	language   string   // language of the next source block
+	block      string   // delimiter of the current block, empty outside blocks
+	cells      []string // cells of the current table
+	columns    int      // number of columns of the current table
+}
+
+func (c *converter) line(line string) {
+	if c.block != "" {
+		c.blockLine(line)
+		return
+	}
+
+	trimmed := strings.TrimSpace(line)
+	if delimiter(trimmed) {
+		c.beginBlock(trimmed)
+		return
+	}
+
+	if match := cardIDRegexp.FindStringSubmatch(trimmed); match != nil {
+		c.lines = append(c.lines, fmt.Sprintf("<!-- mochi-id: %s -->", match[1]))
+		return
+	}
+
+	if strings.HasPrefix(trimmed, "//") {
+		return
+	}
+
+	if match := attributeRegexp.FindStringSubmatch(trimmed); match != nil {
+		c.attributes[strings.ToLower(match[1])] = strings.TrimSpace(match[2])
+		return
+	}
+
+	if match := blockAttrsRegexp.FindStringSubmatch(trimmed); match != nil {
+		c.blockAttributes(match[1])
+		return
+	}
+
+	if match := headingRegexp.FindStringSubmatch(line); match != nil {
+		// the level 0 section is the document title
+		level := len(match[1]) - 1
+		if level == 0 {
+			c.attributes["title"] = match[2]
+			return
+		}
+		c.lines = append(c.lines, fmt.Sprintf("%s %s", strings.Repeat("#", min(level, 6)), inline(match[2])))
+		return
+	}
+
+	if match := imageRegexp.FindStringSubmatch(trimmed); match != nil {
+		c.lines = append(c.lines, image(match[1], match[2]))
+		return
+	}
+
+	if match := listRegexp.FindStringSubmatch(trimmed); match != nil {
+		c.lines = append(c.lines, listItem(match[1], inline(match[2])))
+		return
+	}
+
+	if match := blockTitleRegexp.FindStringSubmatch(trimmed); match != nil {
+		c.lines = append(c.lines, fmt.Sprintf("**%s**", inline(match[1])))
+		return
+	}
+
+	c.lines = append(c.lines, inline(line))
+}
+
+// blockAttributes records the style of the next block: [source,go].
+func (c *converter) blockAttributes(attributes string) {
+	if strings.HasPrefix(attributes, "[") {
+		return // block anchor: [[id]]
+	}
+
+	positional := strings.Split(attributes, ",")
+	c.style = strings.ToLower(strings.TrimSpace(positional[0]))
+	c.language = ""
+	if len(positional) > 1 && c.style == "source" {
+		c.language = strings.TrimSpace(positional[1])
+	}
+}
+
+func (c *converter) beginBlock(delimiter string) {
+	c.block = delimiter
+	switch {
+	case delimiter == "====" || delimiter == "****":
+		// example and sidebar blocks only group their content
+		c.block = ""
+	case delimiter == "----" && c.style == "source":
+		c.lines = append(c.lines, "```"+c.language)
+	case delimiter == "----" || delimiter == "....":
+		c.lines = append(c.lines, "```")
+	case delimiter == "|===":
+		c.cells, c.columns = nil, 0
+	}
+	c.style, c.language = "", ""
+}
+
+func (c *converter) blockLine(line string) {
+	if strings.TrimSpace(line) == c.block {
+		c.endBlock()
+		return
+	}
+
+	switch c.block {
+	case "----", "....", "++++":
+		c.lines = append(c.lines, line)
+	case "////":
+	case "____":
+		c.lines = append(c.lines, strings.TrimSpace("> "+inline(line)))
+	case "|===":
+		c.tableLine(line)
+	}
+}
+
+func (c *converter) endBlock() {
+	switch c.block {
+	case "----", "....":
+		c.lines = append(c.lines, "```")
+	case "|===":
+		c.lines = append(c.lines, table(c.cells, c.columns)...)
+	}
+	c.block = ""
+}
+
+// tableLine records the cells of a table line.
+//
+// The first line sets the number of columns.
+func (c *converter) tableLine(line string) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "|") {
+		return
+	}
+
+	cells := strings.Split(trimmed[1:], "|")
+	for i := range cells {
+		cells[i] = inline(strings.TrimSpace(cells[i]))
+	}
+	if c.columns == 0 {
+		c.columns = len(cells)
+	}
+	c.cells = append(c.cells, cells...)
+}
+
+// delimiter returns whether the line delimits a block.
+func delimiter(line string) bool {
+	switch line {
+	case "----", "....", "____", "====", "****", "++++", "////", "|===":
+		return true
+	default:
+		return false
+	}
+}
+
+// table converts the cells of a table into markdown rows.
+//
+// The first row is the header.
+func table(cells []string, columns int) []string {
+	var rows []string
+	for i := 0; i < len(cells); i += columns {
+		row := cells[i:min(i+columns, len(cells))]
+		rows = append(rows, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			rows = append(rows, "|"+strings.Repeat("---|", columns))
+		}
+	}
+	return rows
+}
+
+// listItem converts a list item, nested by the number of markers.
+func listItem(marker, text string) string {
+	depth := max(len(marker), 1)
+	if strings.HasPrefix(marker, ".") {
+		return fmt.Sprintf("%s1. %s", strings.Repeat("   ", depth-1), text)
+	}
+	return fmt.Sprintf("%s- %s", strings.Repeat("  ", depth-1), text)
+}
+
+func image(target, attributes string) string {
+	alt, _, _ := strings.Cut(attributes, ",")
+	return fmt.Sprintf("![%s](%s)", strings.TrimSpace(alt), target)
+}
+
+// inline converts the images, the links and the inline markup of a line.
+//
+// Code spans are left untouched.
+func inline(line string) string {
+	var sb strings.Builder
+	last := 0
+	for _, match := range codeRegexp.FindAllStringIndex(line, -1) {
+		sb.WriteString(markup(line[last:match[0]]))
+		sb.WriteString(line[match[0]:match[1]])
+		last = match[1]
+	}
+	sb.WriteString(markup(line[last:]))
+	return sb.String()
+}
+
+func markup(text string) string {
+	text = inlineImgRegexp.ReplaceAllStringFunc(text, func(s string) string {
+		match := inlineImgRegexp.FindStringSubmatch(s)
+		return image(match[1], match[2])
+	})
+	text = linkRegexp.ReplaceAllStringFunc(text, func(s string) string {
+		match := linkRegexp.FindStringSubmatch(s)
+		target := match[1] + match[2]
+		description := match[3]
+		if description == "" {
+			description = target
+		}
+		return fmt.Sprintf("[%s](%s)", description, target)
+	})
+	text = replaceAll(strongRegexp, text, "$1**$2**$3")
+	text = unconstrained.ReplaceAllString(text, "*$1*")
+	return replaceAll(emphasisRegexp, text, "$1*$2*$3")
+}
+
+// replaceAll replaces the matches until none is left, since two
+// consecutive matches cannot share the whitespace between them.
+func replaceAll(re *regexp.Regexp, text, repl string) string {
+	for {
+		replaced := re.ReplaceAllString(text, repl)
+		if replaced == text {
+			return text
+		}
+		text = replaced
+	}
+}
@@ -0,0 +1,85 @@
+package asciidoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Convert(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   Document
+	}{
+		{
+			name:   "empty",
+			source: "",
+			want:   Document{Attributes: map[string]string{}, Markdown: []byte("")},
+		},
+		{
+			name:   "attributes and comments",
+			source: "= Capitals\n:mochi-parser: headings\n// a comment\n// mochi-id: abc123\n////\nblock comment\n////\nContent.\n",
+			want: Document{
+				Attributes: map[string]string{"title": "Capitals", "mochi-parser": "headings"},
+				Markdown:   []byte("<!-- mochi-id: abc123 -->\nContent.\n"),
+			},
+		},
+		{
+			name:   "section titles",
+			source: "== Section 1\n=== Section 1.1\nText.\n",
+			want: Document{
+				Attributes: map[string]string{},
+				Markdown:   []byte("# Section 1\n## Section 1.1\nText.\n"),
+			},
+		},
+		{
+			name:   "inline markup",
+			source: "Some *bold* *words*, _italic_ and __un__constrained text with `code *not bold*`.\nA path a_b_c and 2*3*4.\n",
+			want: Document{
+				Attributes: map[string]string{},
+				Markdown:   []byte("Some **bold** **words**, *italic* and *un*constrained text with `code *not bold*`.\nA path a_b_c and 2*3*4.\n"),
+			},
+		},
+		{
+			name:   "links and images",
+			source: "See https://example.com[the _example_] and link:docs/index.html[].\nimage::images/cat.png[A cat, 200]\nAn image:dog.jpg[] inline.\n",
+			want: Document{
+				Attributes: map[string]string{},
+				Markdown:   []byte("See [the *example*](https://example.com) and [docs/index.html](docs/index.html).\n![A cat](images/cat.png)\nAn ![](dog.jpg) inline.\n"),
+			},
+		},
+		{
+			name:   "lists",
+			source: "* one\n** nested\n- dash\n. first\n.. nested\n.Title\n",
+			want: Document{
+				Attributes: map[string]string{},
+				Markdown:   []byte("- one\n  - nested\n- dash\n1. first\n   1. nested\n**Title**\n"),
+			},
+		},
+		{
+			name:   "table",
+			source: "|===\n| Country | Capital\n\n| France | Paris\n|===\n",
+			want: Document{
+				Attributes: map[string]string{},
+				Markdown:   []byte("| Country | Capital |\n|---|---|\n| France | Paris |\n"),
+			},
+		},
+		{
+			name:   "blocks",
+			source: "[source,go]\n----\nfmt.Println(\"*not bold*\")\n----\n[quote, Someone]\n____\nA _quote_.\n____\n====\n....\n== not a heading\n....\n====\n",
+			want: Document{
+				Attributes: map[string]string{},
+				Markdown:   []byte("```go\nfmt.Println(\"*not bold*\")\n```\n> A *quote*.\n```\n== not a heading\n```\n"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert([]byte(tt.source))
+			assert.Equal(t, tt.want.Attributes, got.Attributes)
+			assert.Equal(t, string(tt.want.Markdown), string(got.Markdown))
+		})
+	}
+}
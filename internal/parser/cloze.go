@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// clozeRegexp matches {{c1::answer}}, {{c1::answer::hint}} and ==answer==.
+var clozeRegexp = regexp.MustCompile(`\{\{c(\d+)::([^\n]*?)(?:::([^\n]*?))?\}\}|==([^=\n]+?)==`)
+
+// clozeBase is the interface implemented by the parsers a cloze parser splits.
+type clozeBase interface {
+	cardParser
+	identifier
+}
+
+// highlightGroup is the group of the highlights, which have no cloze number.
+const highlightGroup = 0
+
+// cloze represents a cloze parser.
+//
+// Each card returned by the base parser is split into one card per cloze
+// number, where only the clozes of that number are hidden. Highlights
+// (==answer==) are grouped together in a separate card, after the
+// numbered clozes. Cards without clozes are returned as is.
+type cloze struct {
+	base   clozeBase
+	parser parser.Parser
+}
+
+// newCloze returns a new cloze parser.
+func newCloze(base clozeBase) *cloze {
+	return &cloze{
+		base: base,
+		parser: parser.NewParser(
+			parser.WithBlockParsers(
+				parser.DefaultBlockParsers()...,
+			),
+			parser.WithInlineParsers(
+				parser.DefaultInlineParsers()...,
+			),
+		),
+	}
+}
+
+// parse implements the cardParser interface.
+func (c *cloze) parse(path string, source []byte) (Result, error) {
+	result, err := c.base.parse(path, source)
+	if err != nil || len(result.Cards) == 0 {
+		return result, err
+	}
+
+	cards := make([]Card, 0, len(result.Cards))
+	for _, card := range result.Cards {
+		cards = append(cards, getClozeCards(card, c.clozes([]byte(card.Content)))...)
+	}
+
+	return Result{Deck: result.Deck, Cards: cards}, nil
+}
+
+// blocks implements the identifier interface.
+func (c *cloze) blocks(source []byte) []block {
+	return c.base.blocks(source)
+}
+
+// clozes returns the clozes found in the source, ignoring code.
+func (c *cloze) clozes(source []byte) []parsedCloze {
+	code := c.codeSegments(source)
+	var clozes []parsedCloze
+	for _, match := range clozeRegexp.FindAllSubmatchIndex(source, -1) {
+		if inSegments(code, match[0], match[1]) {
+			continue
+		}
+
+		if match[2] < 0 {
+			clozes = append(clozes, parsedCloze{
+				start:  match[0],
+				stop:   match[1],
+				answer: string(source[match[8]:match[9]]),
+			})
+			continue
+		}
+
+		group, err := strconv.Atoi(string(source[match[2]:match[3]]))
+		if err != nil || group == 0 {
+			continue
+		}
+
+		cloze := parsedCloze{
+			start:  match[0],
+			stop:   match[1],
+			group:  group,
+			answer: string(source[match[4]:match[5]]),
+		}
+		if match[6] >= 0 {
+			cloze.hint = string(source[match[6]:match[7]])
+		}
+		clozes = append(clozes, cloze)
+	}
+	return clozes
+}
+
+func (c *cloze) codeSegments(source []byte) []text.Segment {
+	var segments []text.Segment
+	doc := c.parser.Parse(text.NewReader(source))
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := node.Lines()
+			if lines.Len() > 0 {
+				segments = append(segments, text.NewSegment(lines.At(0).Start, lines.At(lines.Len()-1).Stop))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeSpan:
+			for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+				if t, ok := child.(*ast.Text); ok {
+					segments = append(segments, t.Segment)
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+
+		return ast.WalkContinue, nil
+	})
+	return segments
+}
+
+type parsedCloze struct {
+	start  int
+	stop   int
+	group  int // highlightGroup for highlights
+	answer string
+	hint   string
+}
+
+func getClozeCards(card Card, clozes []parsedCloze) []Card {
+	if len(clozes) == 0 {
+		return []Card{card}
+	}
+
+	var groups []int
+	highlights := false
+	for _, cloze := range clozes {
+		switch {
+		case cloze.group == highlightGroup:
+			highlights = true
+		case !slices.Contains(groups, cloze.group):
+			groups = append(groups, cloze.group)
+		}
+	}
+	slices.Sort(groups)
+	if highlights {
+		groups = append(groups, highlightGroup)
+	}
+
+	cards := make([]Card, 0, len(groups))
+	for _, group := range groups {
+		id := card.ID
+		if id != "" {
+			id = fmt.Sprintf("%s-%s", id, getClozeLabel(group))
+		}
+
+		cards = append(cards, Card{
+			ID:            id,
+			Content:       getClozeContent(card.Content, clozes, group),
+			Fields:        getClozeFields(card.Fields, group),
+			TemplateID:    card.TemplateID,
+			Path:          card.Path,
+			Position:      sanitizePosition(card.Position + getClozePosition(group)),
+			ReviewReverse: card.ReviewReverse,
+		})
+	}
+	return cards
+}
+
+// getClozeLabel returns the label of a group: c1, c2... for the numbered
+// clozes and h for the highlights.
+func getClozeLabel(group int) string {
+	if group == highlightGroup {
+		return "h"
+	}
+	return fmt.Sprintf("c%d", group)
+}
+
+// getClozePosition returns the position suffix of a group, zero-padded
+// so that c10 sorts after c2 and the highlights after the numbered clozes.
+func getClozePosition(group int) string {
+	if group == highlightGroup {
+		return "h"
+	}
+	return fmt.Sprintf("c%04d", group)
+}
+
+// getClozeFields returns a copy of the fields where the name is suffixed
+// with the group, so that the cards of a note have distinct names.
+func getClozeFields(fields map[string]string, group int) map[string]string {
+	fields = maps.Clone(fields)
+	if name, ok := fields["name"]; ok {
+		fields["name"] = fmt.Sprintf("%s (%s)", name, getClozeLabel(group))
+	}
+	return fields
+}
+
+// getClozeContent returns the content in mochi cloze syntax
+// where only the clozes of the group are hidden.
+func getClozeContent(content string, clozes []parsedCloze, group int) string {
+	var sb strings.Builder
+	last := 0
+	for _, cloze := range clozes {
+		sb.WriteString(content[last:cloze.start])
+		switch {
+		case cloze.group != group:
+			sb.WriteString(cloze.answer)
+		case cloze.hint != "":
+			fmt.Fprintf(&sb, "{{%s}} *(%s)*", cloze.answer, cloze.hint)
+		default:
+			fmt.Fprintf(&sb, "{{%s}}", cloze.answer)
+		}
+		last = cloze.stop
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+func inSegments(segments []text.Segment, start, stop int) bool {
+	for _, segment := range segments {
+		if start < segment.Stop && segment.Start < stop {
+			return true
+		}
+	}
+	return false
+}
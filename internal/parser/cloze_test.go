@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_cloze_parse(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   clozeBase
+		path   string
+		source string
+		want   Result
+	}{
+		{
+			name:   "no clozes",
+			base:   newNote(),
+			path:   "/Note.md",
+			source: "Some content.\n",
+			want: Result{Cards: []Card{
+				{
+					Content:  "# Note\n\nSome content.\n",
+					Fields:   nameFields("Note"),
+					Path:     "/Note.md",
+					Position: "Note",
+				},
+			}},
+		},
+		{
+			name:   "numbered clozes",
+			base:   newNote(),
+			path:   "/Capitals.md",
+			source: "The capital of {{c1::France}} is {{c2::Paris::city}}, also {{c1::French}}.\n",
+			want: Result{Cards: []Card{
+				{
+					Content:  "# Capitals\n\nThe capital of {{France}} is Paris, also {{French}}.\n",
+					Fields:   nameFields("Capitals (c1)"),
+					Path:     "/Capitals.md",
+					Position: "Capitalsc0001",
+				},
+				{
+					Content:  "# Capitals\n\nThe capital of France is {{Paris}} *(city)*, also French.\n",
+					Fields:   nameFields("Capitals (c2)"),
+					Path:     "/Capitals.md",
+					Position: "Capitalsc0002",
+				},
+			}},
+		},
+		{
+			name:   "highlights",
+			base:   newNote(),
+			path:   "/Capitals.md",
+			source: "<!-- mochi-id: abc123 -->\nThe capital of ==France== is ==Paris== and {{c1::Berlin}}.\n",
+			want: Result{Cards: []Card{
+				{
+					ID:       "abc123-c1",
+					Content:  "# Capitals\n\nThe capital of France is Paris and {{Berlin}}.\n",
+					Fields:   nameFields("Capitals (c1)"),
+					Path:     "/Capitals.md",
+					Position: "Capitalsc0001",
+				},
+				{
+					ID:       "abc123-h",
+					Content:  "# Capitals\n\nThe capital of {{France}} is {{Paris}} and Berlin.\n",
+					Fields:   nameFields("Capitals (h)"),
+					Path:     "/Capitals.md",
+					Position: "Capitalsh",
+				},
+			}},
+		},
+		{
+			name:   "positions sort by number",
+			base:   newNote(),
+			path:   "/Numbers.md",
+			source: "{{c10::Ten}} and {{c2::two}}.\n",
+			want: Result{Cards: []Card{
+				{
+					Content:  "# Numbers\n\nTen and {{two}}.\n",
+					Fields:   nameFields("Numbers (c2)"),
+					Path:     "/Numbers.md",
+					Position: "Numbersc0002",
+				},
+				{
+					Content:  "# Numbers\n\n{{Ten}} and two.\n",
+					Fields:   nameFields("Numbers (c10)"),
+					Path:     "/Numbers.md",
+					Position: "Numbersc0010",
+				},
+			}},
+		},
+		{
+			name:   "code is ignored",
+			base:   newNote(),
+			path:   "/Code.md",
+			source: "Use `{{c1::inline}}` and {{c2::this}}.\n\n```\n{{c3::fenced}} ==x==\n```\n",
+			want: Result{Cards: []Card{
+				{
+					Content:  "# Code\n\nUse `{{c1::inline}}` and {{this}}.\n\n```\n{{c3::fenced}} ==x==\n```\n",
+					Fields:   nameFields("Code (c2)"),
+					Path:     "/Code.md",
+					Position: "Codec0002",
+				},
+			}},
+		},
+		{
+			name:   "headings",
+			base:   newHeadings(1),
+			path:   "/Headings.md",
+			source: "# Heading 1\n\n{{c1::One}} and {{c2::two}}.\n\n# Heading 2\n\nNo cloze.\n\n# Heading 3\n\n==Three==.\n",
+			want: Result{Deck: "Headings", Cards: []Card{
+				{
+					Content:  "# Heading 1\n\n<details><summary>Headings</summary>Heading 1</details>\n\n{{One}} and two.\n",
+					Fields:   nameFields("Headings > Heading 1 (c1)"),
+					Path:     "/Headings.md",
+					Position: "Headingsmd0000c0001",
+				},
+				{
+					Content:  "# Heading 1\n\n<details><summary>Headings</summary>Heading 1</details>\n\nOne and {{two}}.\n",
+					Fields:   nameFields("Headings > Heading 1 (c2)"),
+					Path:     "/Headings.md",
+					Position: "Headingsmd0000c0002",
+				},
+				{
+					Content:  "# Heading 2\n\n<details><summary>Headings</summary>Heading 2</details>\n\nNo cloze.\n",
+					Fields:   nameFields("Headings > Heading 2"),
+					Path:     "/Headings.md",
+					Position: "Headingsmd0001",
+				},
+				{
+					Content:  "# Heading 3\n\n<details><summary>Headings</summary>Heading 3</details>\n\n{{Three}}.\n",
+					Fields:   nameFields("Headings > Heading 3 (h)"),
+					Path:     "/Headings.md",
+					Position: "Headingsmd0002h",
+				},
+			}},
+		},
+		{
+			name: "empty file",
+			base: newHeadings(1),
+			path: "/Empty.md",
+			want: Result{Deck: "Empty"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCloze(tt.base).parse(tt.path, []byte(tt.source))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_getClozeContent(t *testing.T) {
+	content := "A {{c1::b::hint}} c ==d== e {{c2::f}}"
+	clozes := newCloze(newNote()).clozes([]byte(content))
+
+	tests := []struct {
+		name  string
+		group int
+		want  string
+	}{
+		{name: "highlights", group: highlightGroup, want: "A b c {{d}} e f"},
+		{name: "group 1", group: 1, want: "A {{b}} *(hint)* c d e f"},
+		{name: "group 2", group: 2, want: "A b c d e {{f}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getClozeContent(content, clozes, tt.group))
+		})
+	}
+}
+
+func Test_getClozeFields(t *testing.T) {
+	fields := map[string]string{"name": "Capitals", "notes": "NOTES"}
+	c1 := getClozeFields(fields, 1)
+	c2 := getClozeFields(fields, 2)
+	assert.Equal(t, map[string]string{"name": "Capitals (c1)", "notes": "NOTES"}, c1)
+	assert.Equal(t, map[string]string{"name": "Capitals (c2)", "notes": "NOTES"}, c2)
+	assert.Equal(t, map[string]string{"name": "Capitals", "notes": "NOTES"}, fields)
+	assert.Nil(t, getClozeFields(nil, 1))
+}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// csvTable represents a decoder of delimiter-separated values.
+//
+// The first record holds the headers.
+type csvTable struct {
+	comma rune
+}
+
+func newCSVTable(comma rune) *csvTable {
+	return &csvTable{comma: comma}
+}
+
+// decode implements the tableDecoder interface.
+func (t *csvTable) decode(source []byte) ([]string, [][]string, error) {
+	r := csv.NewReader(bytes.NewReader(source))
+	r.Comma = t.comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = t.comma == '\t'
+
+	records, err := r.ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, nil, err
+	}
+
+	headers := make([]string, 0, len(records[0]))
+	for _, header := range records[0] {
+		headers = append(headers, strings.TrimSpace(header))
+	}
+	return headers, records[1:], nil
+}
+
+// recordsTable represents a decoder of YAML or JSON lists of records.
+//
+// The headers are the keys of the records, in order of appearance.
+// Documents that are not lists, such as package manifests, have no records.
+type recordsTable struct{}
+
+func newRecordsTable() *recordsTable {
+	return &recordsTable{}
+}
+
+// decode implements the tableDecoder interface.
+func (t *recordsTable) decode(source []byte) ([]string, [][]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(source, &doc); err != nil {
+		return nil, nil, err
+	} else if len(doc.Content) == 0 {
+		return nil, nil, nil
+	}
+
+	list := doc.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, nil, nil
+	}
+
+	var headers []string
+	columns := map[string]int{}
+	records := make([]map[string]string, 0, len(list.Content))
+	for _, node := range list.Content {
+		record, keys, err := decodeRecord(node)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, key := range keys {
+			if _, ok := columns[key]; !ok {
+				columns[key] = len(headers)
+				headers = append(headers, key)
+			}
+		}
+		records = append(records, record)
+	}
+
+	rows := make([][]string, 0, len(records))
+	for _, record := range records {
+		row := make([]string, len(headers))
+		for key, value := range record {
+			row[columns[key]] = value
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows, nil
+}
+
+func decodeRecord(node *yaml.Node) (map[string]string, []string, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("records: line %d: expected a record", node.Line)
+	}
+
+	record := make(map[string]string, len(node.Content)/2)
+	keys := make([]string, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			return nil, nil, fmt.Errorf("records: line %d: key %s: expected a scalar value", value.Line, key.Value)
+		}
+		if value.Tag != "!!null" {
+			record[key.Value] = value.Value
+		}
+		keys = append(keys, key.Value)
+	}
+	return record, keys, nil
+}
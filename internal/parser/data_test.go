@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/internal/config"
+)
+
+func Test_csvTable_decode(t *testing.T) {
+	tests := []struct {
+		name        string
+		comma       rune
+		source      string
+		wantHeaders []string
+		wantRows    [][]string
+		err         bool
+	}{
+		{
+			name:  "empty",
+			comma: ',',
+		},
+		{
+			name:        "csv",
+			comma:       ',',
+			source:      "word, translation\nhund,dog\n\"katze, die\",cat\nmaus\n",
+			wantHeaders: []string{"word", "translation"},
+			wantRows:    [][]string{{"hund", "dog"}, {"katze, die", "cat"}, {"maus"}},
+		},
+		{
+			name:        "tsv",
+			comma:       '\t',
+			source:      "word\ttranslation\nhund\t\"dog\"\n",
+			wantHeaders: []string{"word", "translation"},
+			wantRows:    [][]string{{"hund", "dog"}},
+		},
+		{
+			name:   "invalid",
+			comma:  ',',
+			source: "word,translation\n\"hund,dog\n",
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, rows, err := newCSVTable(tt.comma).decode([]byte(tt.source))
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHeaders, headers)
+			assert.Equal(t, tt.wantRows, rows)
+		})
+	}
+}
+
+func Test_recordsTable_decode(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		wantHeaders []string
+		wantRows    [][]string
+		err         bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:        "yaml",
+			source:      "- word: hund\n  translation: dog\n- word: katze\n  gender: f\n  translation: ~\n",
+			wantHeaders: []string{"word", "translation", "gender"},
+			wantRows:    [][]string{{"hund", "dog", ""}, {"katze", "", "f"}},
+		},
+		{
+			name:        "json",
+			source:      `[{"formula": "E = mc^2", "name": "Mass-energy equivalence"}, {"name": "Pi", "formula": 3.14}]`,
+			wantHeaders: []string{"formula", "name"},
+			wantRows:    [][]string{{"E = mc^2", "Mass-energy equivalence"}, {"3.14", "Pi"}},
+		},
+		{
+			name:   "not a list",
+			source: `{"name": "mochi", "version": "1.0.0"}`,
+		},
+		{
+			name:   "not a record",
+			source: "- hund\n",
+			err:    true,
+		},
+		{
+			name:   "nested value",
+			source: "- word: hund\n  examples: [a, b]\n",
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, rows, err := newRecordsTable().decode([]byte(tt.source))
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHeaders, headers)
+			assert.Equal(t, tt.wantRows, rows)
+		})
+	}
+}
+
+func Test_Parser_Parse_data(t *testing.T) {
+	p, err := New(WithDataFiles(), WithTables(map[string]config.TableTemplate{
+		"words": {TemplateID: "TEMPLATE_ID", Name: "word", Fields: map[string]string{"translation": "TRANSLATION_ID"}},
+	}))
+	assert.NoError(t, err)
+
+	source := "word,translation\nhund,dog\n"
+	tests := []struct {
+		name   string
+		parser string
+		path   string
+		want   Result
+		err    bool
+	}{
+		{
+			name:   "template",
+			parser: "words",
+			path:   "/words/German.csv",
+			want: Result{Deck: "German", Cards: []Card{
+				{
+					Fields:     map[string]string{"name": "hund", "TRANSLATION_ID": "dog"},
+					TemplateID: "TEMPLATE_ID",
+					Path:       "/words/German.csv",
+					Position:   "hund",
+				},
+			}},
+		},
+		{
+			name:   "default",
+			parser: "note",
+			path:   "/words/German.csv",
+			want: Result{Deck: "German", Cards: []Card{
+				{
+					Content:  "|Headers|Values|\n|---|---|\n|word|hund|\n|translation|dog|\n",
+					Fields:   nameFields("hund|dog"),
+					Path:     "/words/German.csv",
+					Position: "Germancsv0000",
+				},
+			}},
+		},
+		{
+			name:   "unsupported extension",
+			parser: "note",
+			path:   "/words/German.txt",
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMockReader([]readCall{{path: tt.path, text: source}})
+			got, err := p.Parse(r, tt.parser, tt.path)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_WithDataFiles(t *testing.T) {
+	tables := map[string]config.TableTemplate{
+		"words": {TemplateID: "TEMPLATE_ID", Name: "word"},
+	}
+
+	tests := []struct {
+		name      string
+		options   []Option
+		want      []string
+		templates int // number of table templates of the data files
+	}{
+		{
+			name:    "disabled",
+			options: []Option{WithTables(tables)},
+			want:    []string{".adoc", ".md", ".org"},
+		},
+		{
+			name:    "enabled",
+			options: []Option{WithDataFiles()},
+			want:    []string{".adoc", ".csv", ".json", ".md", ".org", ".tsv", ".yaml", ".yml"},
+		},
+		{
+			name:      "enabled after the tables",
+			options:   []Option{WithTables(tables), WithDataFiles()},
+			want:      []string{".adoc", ".csv", ".json", ".md", ".org", ".tsv", ".yaml", ".yml"},
+			templates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.options...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, p.Extensions())
+			for _, ext := range DataExtensions() {
+				if r, ok := p.registries[ext]; ok {
+					assert.Len(t, r.parsers, tt.templates)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"github.com/leonhfr/mochi/internal/config"
+)
+
+// glossary represents a definition list parser.
+//
+// Each term of the definition lists returns a separate card:
+//
+//	Term
+//	: definition
+//
+// Without template, the sides of the card are the term and its definitions.
+// The cards are positioned by term so that they are sorted alphabetically.
+// The card ID of a term is written at the end of its line.
+type glossary struct {
+	parser parser.Parser
+	config config.GlossaryTemplate
+}
+
+// newGlossary returns a new glossary parser.
+func newGlossary(config config.GlossaryTemplate) *glossary {
+	return &glossary{
+		parser: parser.NewParser(
+			parser.WithBlockParsers(
+				append(
+					parser.DefaultBlockParsers(),
+					util.Prioritized(extension.NewDefinitionListParser(), 101),
+					util.Prioritized(extension.NewDefinitionDescriptionParser(), 102),
+				)...,
+			),
+			parser.WithInlineParsers(
+				parser.DefaultInlineParsers()...,
+			),
+		),
+		config: config,
+	}
+}
+
+type glossaryEntry struct {
+	id          string
+	term        string
+	definitions []string
+}
+
+// parse implements the cardParser interface.
+func (g *glossary) parse(path string, source []byte) (Result, error) {
+	entries, err := g.entries(source)
+	cards := make([]Card, 0, len(entries))
+	for _, entry := range entries {
+		cards = append(cards, g.card(entry, path))
+	}
+
+	return Result{
+		Deck:  getNameFromPath(path),
+		Cards: cards,
+	}, err
+}
+
+// entries returns the terms followed by at least one definition.
+//
+// Consecutive terms share the definitions that follow them.
+func (g *glossary) entries(source []byte) ([]glossaryEntry, error) {
+	var entries []glossaryEntry
+	doc := g.parser.Parse(text.NewReader(source))
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Kind() != east.KindDefinitionList {
+			return ast.WalkContinue, nil
+		}
+
+		var pending []glossaryEntry
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			switch child.Kind() {
+			case east.KindDefinitionTerm:
+				if len(pending) > 0 && len(pending[len(pending)-1].definitions) > 0 {
+					entries = append(entries, pending...)
+					pending = nil
+				}
+				id, term := extractCardID(getNodeText(child, source))
+				pending = append(pending, glossaryEntry{id: id, term: string(bytes.TrimSpace(term))})
+			case east.KindDefinitionDescription:
+				definition := getDefinition(child, source)
+				for i := range pending {
+					pending[i].definitions = append(pending[i].definitions, definition)
+				}
+			}
+		}
+		for _, entry := range pending {
+			if len(entry.definitions) > 0 {
+				entries = append(entries, entry)
+			}
+		}
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return entries, err
+}
+
+// blocks implements the identifier interface.
+//
+// Each term is a block, whose card ID is inserted at the end of its line.
+func (g *glossary) blocks(source []byte) []block {
+	var blocks []block
+	doc := g.parser.Parse(text.NewReader(source))
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Kind() != east.KindDefinitionTerm {
+			return ast.WalkContinue, nil
+		}
+
+		lines := n.Lines()
+		if lines.Len() == 0 {
+			return ast.WalkSkipChildren, nil
+		}
+
+		start, stop := lines.At(0).Start, lines.At(lines.Len()-1).Stop
+		end := start + len(bytes.TrimRight(source[start:stop], "\r\n"))
+		blocks = append(blocks, block{start: start, stop: stop, insert: end, prefix: " "})
+		return ast.WalkSkipChildren, nil
+	})
+	return blocks
+}
+
+func (g *glossary) card(entry glossaryEntry, path string) Card {
+	definition := strings.Join(entry.definitions, "\n\n")
+	card := Card{
+		ID:            entry.id,
+		Fields:        nameFields(entry.term),
+		TemplateID:    g.config.TemplateID,
+		Path:          path,
+		Position:      sanitizePosition(entry.term),
+		ReviewReverse: g.config.Reverse,
+	}
+
+	if g.config.TemplateID == "" {
+		card.Content = fmt.Sprintf("%s\n\n---\n\n%s\n", entry.term, definition)
+		return card
+	}
+
+	card.Fields[g.config.Term] = entry.term
+	card.Fields[g.config.Definition] = definition
+	return card
+}
+
+// getDefinition returns the source of a definition description.
+//
+// The continuation lines are unindented.
+func getDefinition(n ast.Node, source []byte) string {
+	start, stop := getBlockRange(n)
+	if start < 0 {
+		return ""
+	}
+
+	first, rest, _ := bytes.Cut(source[start:stop], []byte("\n"))
+	if len(rest) == 0 {
+		return string(bytes.TrimSpace(first))
+	}
+	return strings.TrimSpace(string(first) + "\n" + string(unindent(rest)))
+}
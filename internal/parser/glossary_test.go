@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/internal/config"
+)
+
+var glossarySource = `# Glossary
+
+Zebra
+: A striped animal.
+
+Apple
+Pomme
+: A fruit.
+: A company.
+
+Some paragraph.
+
+Lorem ipsum
+: Placeholder text,
+  on two lines.
+
+  With a second paragraph.
+`
+
+func Test_glossary_parse(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.GlossaryTemplate
+		path   string
+		source string
+		want   Result
+	}{
+		{
+			name: "empty file",
+			path: "/Empty.md",
+			want: Result{Deck: "Empty", Cards: []Card{}},
+		},
+		{
+			name:   "no definition list",
+			path:   "/Glossary.md",
+			source: "Zebra\n\nA striped animal.\n",
+			want:   Result{Deck: "Glossary", Cards: []Card{}},
+		},
+		{
+			name:   "sides",
+			path:   "/Glossary.md",
+			source: glossarySource,
+			want: Result{Deck: "Glossary", Cards: []Card{
+				{
+					Content:  "Zebra\n\n---\n\nA striped animal.\n",
+					Fields:   nameFields("Zebra"),
+					Path:     "/Glossary.md",
+					Position: "Zebra",
+				},
+				{
+					Content:  "Apple\n\n---\n\nA fruit.\n\nA company.\n",
+					Fields:   nameFields("Apple"),
+					Path:     "/Glossary.md",
+					Position: "Apple",
+				},
+				{
+					Content:  "Pomme\n\n---\n\nA fruit.\n\nA company.\n",
+					Fields:   nameFields("Pomme"),
+					Path:     "/Glossary.md",
+					Position: "Pomme",
+				},
+				{
+					Content:  "Lorem ipsum\n\n---\n\nPlaceholder text,\non two lines.\n\nWith a second paragraph.\n",
+					Fields:   nameFields("Lorem ipsum"),
+					Path:     "/Glossary.md",
+					Position: "Loremipsum",
+				},
+			}},
+		},
+		{
+			name:   "template",
+			config: config.GlossaryTemplate{TemplateID: "TEMPLATE_ID", Term: "TERM_ID", Definition: "DEFINITION_ID", Reverse: true},
+			path:   "/Glossary.md",
+			source: "Zebra\n: A striped animal.\n",
+			want: Result{Deck: "Glossary", Cards: []Card{
+				{
+					Fields:        map[string]string{"name": "Zebra", "TERM_ID": "Zebra", "DEFINITION_ID": "A striped animal."},
+					TemplateID:    "TEMPLATE_ID",
+					Path:          "/Glossary.md",
+					Position:      "Zebra",
+					ReviewReverse: true,
+				},
+			}},
+		},
+		{
+			name:   "non-ASCII terms",
+			path:   "/Glossary.md",
+			source: "Ästhetik\n: Aesthetics.\n\n日本\n: Japan.\n",
+			want: Result{Deck: "Glossary", Cards: []Card{
+				{
+					Content:  "Ästhetik\n\n---\n\nAesthetics.\n",
+					Fields:   nameFields("Ästhetik"),
+					Path:     "/Glossary.md",
+					Position: "Ästhetik",
+				},
+				{
+					Content:  "日本\n\n---\n\nJapan.\n",
+					Fields:   nameFields("日本"),
+					Path:     "/Glossary.md",
+					Position: "日本",
+				},
+			}},
+		},
+		{
+			name:   "reverse",
+			config: config.GlossaryTemplate{Reverse: true},
+			path:   "/Glossary.md",
+			source: "Zebra\n: A striped animal.\n",
+			want: Result{Deck: "Glossary", Cards: []Card{
+				{
+					Content:       "Zebra\n\n---\n\nA striped animal.\n",
+					Fields:        nameFields("Zebra"),
+					Path:          "/Glossary.md",
+					Position:      "Zebra",
+					ReviewReverse: true,
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newGlossary(tt.config).parse(tt.path, []byte(tt.source))
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -36,6 +36,44 @@ func newHeadings(maxLevel int) *headings {
 
 // convert implements the cardParser interface.
 func (h *headings) parse(path string, source []byte) (Result, error) {
+	parsed, err := h.headings(source)
+	cards := getHeadingCards(path, parsed, source)
+
+	return Result{
+		Deck:  getNameFromPath(path),
+		Cards: cards,
+	}, err
+}
+
+// blocks implements the identifier interface.
+func (h *headings) blocks(source []byte) []block {
+	parsed, _ := h.headings(source)
+	if len(parsed) == 1 {
+		return []block{{stop: len(source), suffix: "\n"}}
+	}
+
+	blocks := make([]block, 0, len(parsed))
+	for i, heading := range parsed {
+		stop := len(source)
+		if i < len(parsed)-1 {
+			stop = getHeadingStart(parsed[i+1])
+		}
+
+		if heading.level == 0 {
+			blocks = append(blocks, block{stop: stop, suffix: "\n"})
+			continue
+		}
+
+		end := len(source)
+		if index := bytes.IndexByte(source[heading.stop:], '\n'); index >= 0 {
+			end = heading.stop + index
+		}
+		blocks = append(blocks, block{start: heading.stop, stop: stop, insert: end, prefix: "\n"})
+	}
+	return blocks
+}
+
+func (h *headings) headings(source []byte) ([]parsedHeading, error) {
 	parsed := []parsedHeading{{level: 0}}
 	doc := h.parser.Parse(text.NewReader(source))
 	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -58,12 +96,7 @@ func (h *headings) parse(path string, source []byte) (Result, error) {
 		return ast.WalkContinue, nil
 	})
 
-	cards := getHeadingCards(path, parsed, source)
-
-	return Result{
-		Deck:  getNameFromPath(path),
-		Cards: cards,
-	}, err
+	return parsed, err
 }
 
 func getHeadingCards(path string, headings []parsedHeading, source []byte) []Card {
@@ -103,12 +136,13 @@ func getHeadingCards(path string, headings []parsedHeading, source []byte) []Car
 			stop = getHeadingStart(headings[i+1])
 		}
 
-		content := bytes.TrimSpace(source[start:stop])
+		id, content := extractCardID(bytes.TrimSpace(source[start:stop]))
+		content = bytes.TrimSpace(content)
 		if len(content) == 0 {
 			continue
 		}
 
-		cards = append(cards, newHeadingsCard(titles, path, content, len(cards)))
+		cards = append(cards, newHeadingsCard(titles, id, path, content, len(cards)))
 	}
 
 	return cards
@@ -128,10 +162,11 @@ type parsedHeading struct {
 	stop  int
 }
 
-func newHeadingsCard(headings []string, path string, source []byte, index int) Card {
+func newHeadingsCard(headings []string, id, path string, source []byte, index int) Card {
 	filename := getFilename(path)
 	position := fmt.Sprintf("%s%04d", filename, index)
 	return Card{
+		ID:       id,
 		Content:  getHeadingsContent(headings, string(source)),
 		Fields:   nameFields(getHeadingsName(headings)),
 		Path:     path,
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+)
+
+const cardIDLength = 6 // bytes
+
+var cardIDRegexp = regexp.MustCompile(`<!--\s*mochi-id:\s*([A-Za-z0-9_-]+)\s*-->\n?`)
+
+// identifier is the interface implemented by the card parsers
+// that support persistent card IDs embedded in the source.
+type identifier interface {
+	blocks(source []byte) []block
+}
+
+// block represents a part of the source that produces a card.
+type block struct {
+	start  int    // start of the block content
+	stop   int    // end of the block content
+	insert int    // offset where the card ID should be inserted
+	prefix string // inserted before the marker
+	suffix string // inserted after the marker
+}
+
+// insertCardIDs inserts a new card ID marker in each non-empty block
+// that does not already contain one.
+//
+// Returns whether the source has been modified.
+func insertCardIDs(source []byte, blocks []block) ([]byte, bool, error) {
+	var insertions []insertion
+	for _, b := range blocks {
+		content := source[b.start:b.stop]
+		if len(bytes.TrimSpace(content)) == 0 || cardIDRegexp.Match(content) {
+			continue
+		}
+
+		id, err := newCardID()
+		if err != nil {
+			return nil, false, err
+		}
+
+		insertions = append(insertions, insertion{
+			offset: b.insert,
+			text:   fmt.Sprintf("%s%s%s", b.prefix, cardIDMarker(id), b.suffix),
+		})
+	}
+
+	if len(insertions) == 0 {
+		return source, false, nil
+	}
+
+	return applyInsertions(source, insertions), true, nil
+}
+
+type insertion struct {
+	offset int
+	text   string
+}
+
+// applyInsertions expects the insertions sorted by offset.
+func applyInsertions(source []byte, insertions []insertion) []byte {
+	result := make([]byte, 0, len(source)+len(insertions)*32)
+	last := 0
+	for _, ins := range insertions {
+		result = append(result, source[last:ins.offset]...)
+		result = append(result, ins.text...)
+		last = ins.offset
+	}
+	return append(result, source[last:]...)
+}
+
+// extractCardID returns the card ID found in the content
+// and the content stripped of the marker.
+func extractCardID(content []byte) (string, []byte) {
+	match := cardIDRegexp.FindSubmatchIndex(content)
+	if match == nil {
+		return "", content
+	}
+
+	id := string(content[match[2]:match[3]])
+	start, stop := match[0], match[1]
+	// a marker at the end of a line keeps the line break
+	if start > 0 && content[start-1] != '\n' {
+		start = len(bytes.TrimRight(content[:start], " \t"))
+		if content[stop-1] == '\n' {
+			stop--
+		}
+	}
+
+	stripped := make([]byte, 0, len(content)-(stop-start))
+	stripped = append(stripped, content[:start]...)
+	stripped = append(stripped, content[stop:]...)
+	return id, stripped
+}
+
+func cardIDMarker(id string) string {
+	return fmt.Sprintf("<!-- mochi-id: %s -->", id)
+}
+
+func newCardID() (string, error) {
+	b := make([]byte, cardIDLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
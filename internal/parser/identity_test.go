@@ -0,0 +1,305 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonhfr/mochi/internal/config"
+)
+
+var (
+	_ identifier = &note{}
+	_ identifier = &headings{}
+	_ identifier = &list{}
+	_ identifier = &qa{}
+	_ identifier = &table{}
+	_ identifier = &tableTemplate{}
+	_ identifier = &glossary{}
+)
+
+func Test_insertCardIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		parser  identifier
+		source  string
+		want    string
+		updated bool
+	}{
+		{
+			name:    "empty note",
+			parser:  newNote(),
+			source:  "",
+			want:    "",
+			updated: false,
+		},
+		{
+			name:    "note",
+			parser:  newNote(),
+			source:  "Paragraph.\n",
+			want:    "<!-- mochi-id: ID -->\nParagraph.\n",
+			updated: true,
+		},
+		{
+			name:    "note with id",
+			parser:  newNote(),
+			source:  "Paragraph.\n<!-- mochi-id: abc -->\n",
+			want:    "Paragraph.\n<!-- mochi-id: abc -->\n",
+			updated: false,
+		},
+		{
+			name:    "headings",
+			parser:  newHeadings(1),
+			source:  "Preamble.\n\n# Heading 1\n\nContent 1.\n\n# Heading 2\n<!-- mochi-id: abc -->\n\nContent 2.\n\n# Heading 3\n\n# Heading 4\n\nContent 4.",
+			want:    "<!-- mochi-id: ID -->\nPreamble.\n\n# Heading 1\n<!-- mochi-id: ID -->\n\nContent 1.\n\n# Heading 2\n<!-- mochi-id: abc -->\n\nContent 2.\n\n# Heading 3\n\n# Heading 4\n<!-- mochi-id: ID -->\n\nContent 4.",
+			updated: true,
+		},
+		{
+			name:    "list",
+			parser:  newList(1),
+			source:  "- Front 1\n  - Back 1\n  - Back 2\n- Front 2\n  - Back 3\n  <!-- mochi-id: abc -->\n- No back\n- Front 3\n    1. Back 4",
+			want:    "- Front 1\n  - Back 1\n  - Back 2\n  <!-- mochi-id: ID -->\n- Front 2\n  - Back 3\n  <!-- mochi-id: abc -->\n- No back\n- Front 3\n    1. Back 4\n    <!-- mochi-id: ID -->",
+			updated: true,
+		},
+		{
+			name:    "table",
+			parser:  newTable(newMarkdownTable()),
+			source:  "| Country | Capital |\n|---|---|\n| France | Paris |\n| Germany | Berlin <!-- mochi-id: abc --> |\nItaly | Rome\n",
+			want:    "| Country | Capital |\n|---|---|\n| France | Paris <!-- mochi-id: ID --> |\n| Germany | Berlin <!-- mochi-id: abc --> |\nItaly | Rome <!-- mochi-id: ID -->\n",
+			updated: true,
+		},
+		{
+			name:    "table data file",
+			parser:  newTable(newCSVTable(',')),
+			source:  "Country,Capital\nFrance,Paris\n",
+			want:    "Country,Capital\nFrance,Paris\n",
+			updated: false,
+		},
+		{
+			name:    "glossary",
+			parser:  newGlossary(config.GlossaryTemplate{}),
+			source:  "Term 1\nTerm 2 <!-- mochi-id: abc -->\n: Definition.\n\nTerm 3\n: Definition.",
+			want:    "Term 1 <!-- mochi-id: ID -->\nTerm 2 <!-- mochi-id: abc -->\n: Definition.\n\nTerm 3 <!-- mochi-id: ID -->\n: Definition.",
+			updated: true,
+		},
+		{
+			name:    "qa",
+			parser:  newQA(),
+			source:  "Q: Question 1\nA: Answer 1\nQ: Question 2 <!-- mochi-id: abc -->\nA: Answer 2\n\nFront 1 :: Back 1\nFront 2 ::: Back 2\n\n```go\ncode\n```\n?\nAnswer 3",
+			want:    "Q: Question 1 <!-- mochi-id: ID -->\nA: Answer 1\nQ: Question 2 <!-- mochi-id: abc -->\nA: Answer 2\n\nFront 1 :: Back 1 <!-- mochi-id: ID -->\nFront 2 ::: Back 2 <!-- mochi-id: ID -->\n\n<!-- mochi-id: ID -->\n```go\ncode\n```\n?\nAnswer 3",
+			updated: true,
+		},
+	}
+
+	generated := regexp.MustCompile(`mochi-id: [0-9a-f]{12} `)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := []byte(tt.source)
+			got, updated, err := insertCardIDs(source, tt.parser.blocks(source))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.updated, updated)
+			assert.Equal(t, tt.want, generated.ReplaceAllString(string(got), "mochi-id: ID "))
+		})
+	}
+}
+
+func Test_extractCardID(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		id      string
+		want    string
+	}{
+		{
+			name:    "no id",
+			content: "Content.\n",
+			want:    "Content.\n",
+		},
+		{
+			name:    "id",
+			content: "<!-- mochi-id: 1a2b3c -->\nContent.\n",
+			id:      "1a2b3c",
+			want:    "Content.\n",
+		},
+		{
+			name:    "id with spaces",
+			content: "Content.\n<!--mochi-id:1a2b3c   -->",
+			id:      "1a2b3c",
+			want:    "Content.\n",
+		},
+		{
+			name:    "id at the end of a line",
+			content: "Question <!-- mochi-id: 1a2b3c -->\nContinued.\n",
+			id:      "1a2b3c",
+			want:    "Question\nContinued.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, got := extractCardID([]byte(tt.content))
+			assert.Equal(t, tt.id, id)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func Test_headings_parse_cardIDs(t *testing.T) {
+	source := "# Heading 1\n<!-- mochi-id: abc -->\n\nContent 1.\n\n# Heading 2\n\nContent 2.\n"
+	want := Result{Deck: "Headings", Cards: []Card{
+		{
+			ID:       "abc",
+			Content:  "# Heading 1\n\n<details><summary>Headings</summary>Heading 1</details>\n\nContent 1.\n",
+			Fields:   nameFields("Headings > Heading 1"),
+			Path:     "/Headings.md",
+			Position: "Headingsmd0000",
+		},
+		{
+			Content:  "# Heading 2\n\n<details><summary>Headings</summary>Heading 2</details>\n\nContent 2.\n",
+			Fields:   nameFields("Headings > Heading 2"),
+			Path:     "/Headings.md",
+			Position: "Headingsmd0001",
+		},
+	}}
+
+	got, err := newHeadings(1).parse("/Headings.md", []byte(source))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func Test_list_parse_cardIDs(t *testing.T) {
+	source := "- Front 1\n  - Back 1\n  <!-- mochi-id: abc -->\n- Front 2\n  - Back 2\n"
+	want := Result{Deck: "List", Cards: []Card{
+		{
+			ID:       "abc",
+			Content:  "# Front 1\n\n<details><summary>Headings</summary>Front 1</details>\n\n- Back 1\n",
+			Fields:   nameFields("List > Front 1"),
+			Path:     "/List.md",
+			Position: "Listmd0000",
+		},
+		{
+			Content:  "# Front 2\n\n<details><summary>Headings</summary>Front 2</details>\n\n- Back 2\n",
+			Fields:   nameFields("List > Front 2"),
+			Path:     "/List.md",
+			Position: "Listmd0001",
+		},
+	}}
+
+	got, err := newList(1).parse("/List.md", []byte(source))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func Test_qa_parse_cardIDs(t *testing.T) {
+	source := "Q: Question 1 <!-- mochi-id: abc -->\nContinued.\nA: Answer 1\n\nFront :: Back <!-- mochi-id: def -->\n\n<!-- mochi-id: ghi -->\n```go\ncode\n```\n?\nAnswer 3\n"
+	want := Result{Deck: "QA", Cards: []Card{
+		{
+			ID:       "abc",
+			Content:  "Question 1\nContinued.\n\n---\n\nAnswer 1\n",
+			Fields:   nameFields("Question 1"),
+			Path:     "/QA.md",
+			Position: "QAmd0000",
+		},
+		{
+			ID:       "def",
+			Content:  "Front\n\n---\n\nBack\n",
+			Fields:   nameFields("Front"),
+			Path:     "/QA.md",
+			Position: "QAmd0001",
+		},
+		{
+			ID:       "ghi",
+			Content:  "```go\ncode\n```\n\n---\n\nAnswer 3\n",
+			Fields:   nameFields("```go"),
+			Path:     "/QA.md",
+			Position: "QAmd0002",
+		},
+	}}
+
+	got, err := newQA().parse("/QA.md", []byte(source))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func Test_table_parse_cardIDs(t *testing.T) {
+	source := "| Country | Capital |\n|---|---|\n| France | Paris <!-- mochi-id: abc --> |\n"
+	want := Result{Deck: "Table", Cards: []Card{
+		{
+			ID:       "abc",
+			Content:  "|Headers|Values|\n|---|---|\n|Country|France|\n|Capital|Paris|\n",
+			Fields:   nameFields("France|Paris"),
+			Path:     "/Table.md",
+			Position: "Tablemd0000",
+		},
+	}}
+
+	got, err := newTable(newMarkdownTable()).parse("/Table.md", []byte(source))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func Test_glossary_parse_cardIDs(t *testing.T) {
+	source := "Term <!-- mochi-id: abc -->\n: Definition.\n"
+	want := Result{Deck: "Glossary", Cards: []Card{
+		{
+			ID:       "abc",
+			Content:  "Term\n\n---\n\nDefinition.\n",
+			Fields:   nameFields("Term"),
+			Path:     "/Glossary.md",
+			Position: "Term",
+		},
+	}}
+
+	got, err := newGlossary(config.GlossaryTemplate{}).parse("/Glossary.md", []byte(source))
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func Test_Parser_WriteCardIDs(t *testing.T) {
+	sources := map[string]string{
+		"/notes/First.md":  "---\nmochi-parser: note\n---\nFirst.\n",
+		"/notes/Second.md": "Second.\n",
+		"/notes/Third.md":  "<!-- mochi-id: abc -->\nThird.\n",
+	}
+	var calls []readCall
+	for path, text := range sources {
+		calls = append(calls, readCall{path: path, text: text})
+	}
+	r := newMockReader(calls)
+	w := testWriter{}
+
+	p, err := New(WithCardIDs(w))
+	require.NoError(t, err)
+
+	for path := range sources {
+		result, err := p.Parse(r, "note", path)
+		require.NoError(t, err)
+		require.Len(t, result.Cards, 1)
+		assert.NotEmpty(t, result.Cards[0].ID)
+	}
+	assert.Empty(t, w, "not written while parsing")
+
+	err = p.WriteCardIDs(func(path string) bool { return path == "/notes/Second.md" })
+	require.NoError(t, err)
+
+	require.Len(t, w, 1, "skipped and unchanged sources not written")
+	want := regexp.MustCompile(`^---\nmochi-parser: note\n---\n<!-- mochi-id: [0-9a-f]{12} -->\nFirst\.\n$`)
+	assert.Regexp(t, want, w["/notes/First.md"].String())
+}
+
+// testWriter records the written files.
+type testWriter map[string]*bytes.Buffer
+
+func (w testWriter) Write(path string) (io.WriteCloser, error) {
+	w[path] = &bytes.Buffer{}
+	return nopCloser{w[path]}, nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// list represents a list parser.
+//
+// Each list item at a determined depth with a nested list returns a separate card.
+// The item text is the front and the nested list is the back. The card names
+// are formatted from the card name, the parent headings and the item text.
+type list struct {
+	parser parser.Parser
+	depth  int
+}
+
+// newList returns a new list parser.
+func newList(depth int) *list {
+	return &list{
+		parser: parser.NewParser(
+			parser.WithBlockParsers(
+				parser.DefaultBlockParsers()...,
+			),
+			parser.WithInlineParsers(
+				parser.DefaultInlineParsers()...,
+			),
+		),
+		depth: depth,
+	}
+}
+
+// parse implements the cardParser interface.
+func (l *list) parse(path string, source []byte) (Result, error) {
+	cards := []Card{}
+	titles := []string{getNameFromPath(path)}
+
+	doc := l.parser.Parse(text.NewReader(source))
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Heading:
+			titles = appendHeading(titles, node.Level, string(getNodeText(node, source)))
+		case *ast.ListItem:
+			if getListItemDepth(node) != l.depth {
+				return ast.WalkContinue, nil
+			}
+			if card, ok := l.card(node, titles, path, source, len(cards)); ok {
+				cards = append(cards, card)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return Result{
+		Deck:  getNameFromPath(path),
+		Cards: cards,
+	}, err
+}
+
+// blocks implements the identifier interface.
+//
+// The card ID of an item is inserted after its nested list,
+// at the indentation of the nested list.
+func (l *list) blocks(source []byte) []block {
+	var blocks []block
+	doc := l.parser.Parse(text.NewReader(source))
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		item, ok := n.(*ast.ListItem)
+		if !entering || !ok || getListItemDepth(item) != l.depth {
+			return ast.WalkContinue, nil
+		}
+
+		var nested ast.Node
+		for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+			if child.Kind() == ast.KindList {
+				nested = child
+			}
+		}
+		if nested == nil {
+			return ast.WalkSkipChildren, nil
+		}
+
+		start, stop := getBlockRange(item)
+		nestedStart, _ := getBlockRange(nested)
+		if start < 0 || nestedStart < 0 {
+			return ast.WalkSkipChildren, nil
+		}
+
+		line := source[bytes.LastIndexByte(source[:nestedStart], '\n')+1:]
+		indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+		b := block{start: start, stop: stop, insert: stop, prefix: string(indent), suffix: "\n"}
+		if stop > 0 && source[stop-1] != '\n' {
+			b.prefix = "\n" + b.prefix
+			b.suffix = ""
+		}
+		blocks = append(blocks, b)
+		return ast.WalkSkipChildren, nil
+	})
+	return blocks
+}
+
+func (l *list) card(item *ast.ListItem, titles []string, path string, source []byte, index int) (Card, bool) {
+	var front, id string
+	var back []byte
+	for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+		switch child.Kind() {
+		case ast.KindTextBlock, ast.KindParagraph:
+			if front == "" {
+				front = string(getNodeText(child, source))
+			}
+		case ast.KindList:
+			id, back = extractCardID(getNestedList(child, source))
+		case ast.KindHTMLBlock:
+			if blockID, _ := extractCardID(getNodeText(child, source)); blockID != "" {
+				id = blockID
+			}
+		}
+	}
+
+	back = bytes.TrimSpace(back)
+	if front == "" || len(back) == 0 {
+		return Card{}, false
+	}
+
+	headings := append(titles[:len(titles):len(titles)], front)
+	return newHeadingsCard(headings, id, path, back, index), true
+}
+
+// appendHeading returns the titles with the heading at its level,
+// dropping the headings of the same or lower levels.
+func appendHeading(titles []string, level int, heading string) []string {
+	for level < len(titles) {
+		titles = titles[:len(titles)-1]
+	}
+	for level > len(titles) {
+		titles = append(titles, "")
+	}
+	return append(titles, heading)
+}
+
+// getListItemDepth returns the number of list items the item is nested in, plus one.
+func getListItemDepth(item ast.Node) int {
+	depth := 0
+	for n := item; n != nil; n = n.Parent() {
+		if n.Kind() == ast.KindListItem {
+			depth++
+		}
+	}
+	return depth
+}
+
+// getNodeText returns the lines of a block node joined by spaces.
+func getNodeText(n ast.Node, source []byte) []byte {
+	lines := n.Lines()
+	parts := make([][]byte, 0, lines.Len())
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		parts = append(parts, bytes.TrimSpace(segment.Value(source)))
+	}
+	return bytes.Join(parts, []byte(" "))
+}
+
+// getNestedList returns the source of a nested list, unindented.
+func getNestedList(n ast.Node, source []byte) []byte {
+	start, stop := getBlockRange(n)
+	if start < 0 {
+		return nil
+	}
+
+	start = bytes.LastIndexByte(source[:start], '\n') + 1
+	return unindent(source[start:stop])
+}
+
+// getBlockRange returns the range of the lines of the node and its descendants.
+//
+// The start is negative when the blocks have no lines.
+func getBlockRange(n ast.Node) (int, int) {
+	start, stop := -1, -1
+	_ = ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || child.Type() != ast.TypeBlock {
+			return ast.WalkContinue, nil
+		}
+		if lines := child.Lines(); lines.Len() > 0 {
+			if first := lines.At(0); start < 0 || first.Start < start {
+				start = first.Start
+			}
+			if last := lines.At(lines.Len() - 1); last.Stop > stop {
+				stop = last.Stop
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return start, stop
+}
+
+// unindent removes the indentation of the first non-blank line from every line.
+func unindent(source []byte) []byte {
+	lines := strings.Split(string(source), "\n")
+	var prefix string
+	for _, line := range lines {
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" {
+			prefix = line[:len(line)-len(trimmed)]
+			break
+		}
+	}
+
+	indent := len(prefix)
+	for i, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			lines[i] = line[indent:]
+		} else {
+			lines[i] = strings.TrimLeft(line, " \t")
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var listSource = `# Capitals
+
+## Europe
+
+- France
+  - Paris
+  - **Big**
+    - nested
+- Lonely
+- Spain
+  - Madrid
+
+# Numbers
+
+* Two
+  lines
+  1. one
+  2. two
+`
+
+func Test_list_parse(t *testing.T) {
+	tests := []struct {
+		name   string
+		depth  int
+		path   string
+		source string
+		want   Result
+	}{
+		{
+			name:  "empty file",
+			depth: 1,
+			path:  "/Empty.md",
+			want:  Result{Deck: "Empty", Cards: []Card{}},
+		},
+		{
+			name:   "no nested list",
+			depth:  1,
+			path:   "/List.md",
+			source: "- one\n- two\n",
+			want:   Result{Deck: "List", Cards: []Card{}},
+		},
+		{
+			name:   "depth 1",
+			depth:  1,
+			path:   "/Notes.md",
+			source: listSource,
+			want: Result{Deck: "Notes", Cards: []Card{
+				{
+					Content:  "# France\n\n<details><summary>Headings</summary>Capitals > Europe > France</details>\n\n- Paris\n- **Big**\n  - nested\n",
+					Fields:   nameFields("Notes > Capitals > Europe > France"),
+					Path:     "/Notes.md",
+					Position: "Notesmd0000",
+				},
+				{
+					Content:  "# Spain\n\n<details><summary>Headings</summary>Capitals > Europe > Spain</details>\n\n- Madrid\n",
+					Fields:   nameFields("Notes > Capitals > Europe > Spain"),
+					Path:     "/Notes.md",
+					Position: "Notesmd0001",
+				},
+				{
+					Content:  "# Two lines\n\n<details><summary>Headings</summary>Numbers > Two lines</details>\n\n1. one\n2. two\n",
+					Fields:   nameFields("Notes > Numbers > Two lines"),
+					Path:     "/Notes.md",
+					Position: "Notesmd0002",
+				},
+			}},
+		},
+		{
+			name:   "depth 2",
+			depth:  2,
+			path:   "/Notes.md",
+			source: listSource,
+			want: Result{Deck: "Notes", Cards: []Card{
+				{
+					Content:  "# **Big**\n\n<details><summary>Headings</summary>Capitals > Europe > **Big**</details>\n\n- nested\n",
+					Fields:   nameFields("Notes > Capitals > Europe > **Big**"),
+					Path:     "/Notes.md",
+					Position: "Notesmd0000",
+				},
+			}},
+		},
+		{
+			name:   "without headings",
+			depth:  1,
+			path:   "/Notes.md",
+			source: "- Question\n  - Answer\n",
+			want: Result{Deck: "Notes", Cards: []Card{
+				{
+					Content:  "# Question\n\n<details><summary>Headings</summary>Question</details>\n\n- Answer\n",
+					Fields:   nameFields("Notes > Question"),
+					Path:     "/Notes.md",
+					Position: "Notesmd0000",
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newList(tt.depth).parse(tt.path, []byte(tt.source))
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -19,9 +19,16 @@ func (n *note) parse(path string, source []byte) (Result, error) {
 	return Result{Cards: []Card{newNoteCard(name, path, source)}}, nil
 }
 
+// blocks implements the identifier interface.
+func (n *note) blocks(source []byte) []block {
+	return []block{{stop: len(source), suffix: "\n"}}
+}
+
 func newNoteCard(name, path string, source []byte) Card {
+	id, source := extractCardID(source)
 	content := fmt.Sprintf("# %s\n\n%s", name, string(source))
 	return Card{
+		ID:       id,
 		Content:  content,
 		Fields:   nameFields(name),
 		Path:     path,
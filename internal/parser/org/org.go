@@ -0,0 +1,271 @@
+// Package org converts org-mode documents to markdown.
+package org
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	keywordRegexp   = regexp.MustCompile(`^#\+([A-Za-z_-]+):\s*(.*)$`)
+	blockRegexp     = regexp.MustCompile(`(?i)^#\+(begin|end)_([a-z]+)\s*(.*)$`)
+	headingRegexp   = regexp.MustCompile(`^(\*+)\s+(.*?)(?:\s+(:[\w@#%:]+:))?\s*$`)
+	priorityRegexp  = regexp.MustCompile(`^\[#[A-Za-z0-9]+\]\s*`)
+	drawerRegexp    = regexp.MustCompile(`^:([\w-]+):$`)
+	cardIDRegexp    = regexp.MustCompile(`^#\s+mochi-id:\s*([A-Za-z0-9_-]+)\s*$`)
+	listRegexp      = regexp.MustCompile(`^(\s*)(?:[-+]|(\d+)[.)])\s+(.*)$`)
+	tableRuleRegexp = regexp.MustCompile(`^\s*\|[-+|\s]*$`)
+	linkRegexp      = regexp.MustCompile(`\[\[([^\]]+)\](?:\[([^\]]+)\])?\]`)
+	verbatimRegexp  = regexp.MustCompile(`(^|[\s({'"])[=~]([^\s=~](?:[^=~]*[^\s=~])?)[=~]($|[\s\-.,:!?;'")}])`)
+	emphasisRegexp  = regexp.MustCompile(`(^|[\s({'"])([*/+])([^\s*/+](?:[^*/+]*[^\s*/+])?)([*/+])($|[\s\-.,:!?;'")}])`)
+)
+
+var imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp"}
+
+// todoKeywords are the keywords that declare the TODO states of the headings.
+var todoKeywords = []string{"todo", "seq_todo", "typ_todo"}
+
+// Document represents a converted org-mode document.
+type Document struct {
+	Keywords map[string]string // lowercase keys, e.g. map[title]Title
+	Markdown []byte
+}
+
+// Convert converts an org-mode document to markdown.
+//
+// Headings, lists, tables, blocks, links and inline markup are converted.
+// The #+KEY: value keywords are extracted and removed from the content,
+// as are the drawers and the comments except the mochi-id markers.
+// The TODO keywords and the priority cookies are removed from the headings.
+func Convert(source []byte) Document {
+	c := converter{keywords: map[string]string{}, todo: map[string]bool{"TODO": true, "DONE": true}}
+	for _, line := range strings.Split(string(source), "\n") {
+		c.line(line)
+	}
+
+	markdown := strings.TrimLeft(strings.Join(c.lines, "\n"), "\n")
+	return Document{
+		Keywords: c.keywords,
+		Markdown: []byte(markdown),
+	}
+}
+
+type converter struct {
+	keywords map[string]string
+	todo     map[string]bool // TODO states, e.g. TODO and DONE
+	lines    []string
+	block    string // name of the current block, empty outside blocks
+	drawer   bool   // whether the current line is inside a drawer
+}
+
+func (c *converter) line(line string) {
+	if c.block != "" {
+		c.blockLine(line)
+		return
+	}
+
+	trimmed := strings.TrimSpace(line)
+	if c.drawer {
+		c.drawer = !strings.EqualFold(trimmed, ":end:")
+		return
+	}
+
+	if match := drawerRegexp.FindStringSubmatch(trimmed); match != nil && !strings.EqualFold(match[1], "end") {
+		c.drawer = true
+		return
+	}
+
+	if match := blockRegexp.FindStringSubmatch(trimmed); match != nil && strings.EqualFold(match[1], "begin") {
+		c.beginBlock(strings.ToLower(match[2]), match[3])
+		return
+	}
+
+	if match := keywordRegexp.FindStringSubmatch(trimmed); match != nil {
+		c.keyword(strings.ToLower(match[1]), strings.TrimSpace(match[2]))
+		return
+	}
+
+	if match := cardIDRegexp.FindStringSubmatch(trimmed); match != nil {
+		c.lines = append(c.lines, fmt.Sprintf("<!-- mochi-id: %s -->", match[1]))
+		return
+	}
+
+	if trimmed == "#" || strings.HasPrefix(trimmed, "# ") {
+		return
+	}
+
+	if match := headingRegexp.FindStringSubmatch(line); match != nil {
+		level := min(len(match[1]), 6)
+		c.lines = append(c.lines, strings.TrimSpace(fmt.Sprintf("%s %s", strings.Repeat("#", level), inline(c.title(match[2])))))
+		return
+	}
+
+	if strings.HasPrefix(trimmed, "|") {
+		c.lines = append(c.lines, tableLine(trimmed))
+		return
+	}
+
+	if match := listRegexp.FindStringSubmatch(line); match != nil {
+		marker := "-"
+		if match[2] != "" {
+			marker = match[2] + "."
+		}
+		c.lines = append(c.lines, fmt.Sprintf("%s%s %s", match[1], marker, inline(match[3])))
+		return
+	}
+
+	c.lines = append(c.lines, inline(line))
+}
+
+// keyword records a keyword, and the TODO states it declares.
+func (c *converter) keyword(key, value string) {
+	c.keywords[key] = value
+	if !slices.Contains(todoKeywords, key) {
+		return
+	}
+
+	for _, state := range strings.Fields(value) {
+		// the states may declare a fast access key: DONE(d)
+		state, _, _ = strings.Cut(state, "(")
+		if state != "|" {
+			c.todo[state] = true
+		}
+	}
+}
+
+// title removes the TODO keyword and the priority cookie of a heading.
+func (c *converter) title(heading string) string {
+	if state, rest, _ := strings.Cut(heading, " "); c.todo[state] {
+		heading = strings.TrimLeft(rest, " ")
+	} else if c.todo[heading] {
+		heading = ""
+	}
+	return priorityRegexp.ReplaceAllString(heading, "")
+}
+
+func (c *converter) beginBlock(name, args string) {
+	c.block = name
+	switch name {
+	case "src":
+		language, _, _ := strings.Cut(strings.TrimSpace(args), " ")
+		c.lines = append(c.lines, "```"+language)
+	case "quote":
+	default:
+		c.lines = append(c.lines, "```")
+	}
+}
+
+func (c *converter) blockLine(line string) {
+	if match := blockRegexp.FindStringSubmatch(strings.TrimSpace(line)); match != nil &&
+		strings.EqualFold(match[1], "end") && strings.EqualFold(match[2], c.block) {
+		if c.block != "quote" {
+			c.lines = append(c.lines, "```")
+		}
+		c.block = ""
+		return
+	}
+
+	if c.block == "quote" {
+		c.lines = append(c.lines, strings.TrimSpace("> "+inline(line)))
+		return
+	}
+
+	c.lines = append(c.lines, line)
+}
+
+// tableLine converts a table row, or a table rule into the markdown delimiter row.
+func tableLine(line string) string {
+	if !tableRuleRegexp.MatchString(line) {
+		return inline(line)
+	}
+
+	columns := strings.Count(strings.Trim(line, "|"), "+") + 1
+	return "|" + strings.Repeat("---|", columns)
+}
+
+// inline converts the links and the inline markup of a line.
+//
+// Verbatim and code spans are left untouched.
+func inline(line string) string {
+	var sb strings.Builder
+	for {
+		match := verbatimRegexp.FindStringSubmatchIndex(line)
+		if match == nil {
+			break
+		}
+		sb.WriteString(markup(line[:match[3]]))
+		fmt.Fprintf(&sb, "`%s`", line[match[4]:match[5]])
+		line = line[match[6]:]
+	}
+	sb.WriteString(markup(line))
+	return sb.String()
+}
+
+func markup(text string) string {
+	var sb strings.Builder
+	last := 0
+	for _, match := range linkRegexp.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(emphasis(text[last:match[0]]))
+		description := ""
+		if match[4] >= 0 {
+			description = emphasis(text[match[4]:match[5]])
+		}
+		sb.WriteString(link(text[match[2]:match[3]], description))
+		last = match[1]
+	}
+	sb.WriteString(emphasis(text[last:]))
+	return sb.String()
+}
+
+func link(target, description string) string {
+	target = strings.TrimPrefix(target, "file:")
+	if description == "" && isImage(target) {
+		return fmt.Sprintf("![](%s)", target)
+	}
+	if description == "" {
+		description = target
+	}
+	return fmt.Sprintf("[%s](%s)", description, target)
+}
+
+func emphasis(text string) string {
+	var sb strings.Builder
+	for {
+		match := emphasisRegexp.FindStringSubmatchIndex(text)
+		if match == nil {
+			break
+		}
+
+		opening, closing := text[match[4]:match[5]], text[match[8]:match[9]]
+		if opening != closing {
+			sb.WriteString(text[:match[5]])
+			text = text[match[5]:]
+			continue
+		}
+
+		sb.WriteString(text[:match[3]])
+		marker := emphasisMarker(opening)
+		sb.WriteString(marker + text[match[6]:match[7]] + marker)
+		text = text[match[10]:]
+	}
+	sb.WriteString(text)
+	return sb.String()
+}
+
+func emphasisMarker(delimiter string) string {
+	switch delimiter {
+	case "*":
+		return "**"
+	case "/":
+		return "*"
+	default:
+		return "~~"
+	}
+}
+
+func isImage(target string) bool {
+	return slices.Contains(imageExtensions, strings.ToLower(filepath.Ext(target)))
+}
@@ -0,0 +1,101 @@
+package org
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Convert(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   Document
+	}{
+		{
+			name:   "empty",
+			source: "",
+			want:   Document{Keywords: map[string]string{}, Markdown: []byte("")},
+		},
+		{
+			name:   "keywords and comments",
+			source: "#+TITLE: Capitals\n#+mochi-parser: headings\n# a comment\n# mochi-id: abc123\nContent.\n",
+			want: Document{
+				Keywords: map[string]string{"title": "Capitals", "mochi-parser": "headings"},
+				Markdown: []byte("<!-- mochi-id: abc123 -->\nContent.\n"),
+			},
+		},
+		{
+			name:   "headings",
+			source: "* Heading 1\n** TODO Heading 1.1  :tag:other:\nText.\n",
+			want: Document{
+				Keywords: map[string]string{},
+				Markdown: []byte("# Heading 1\n## Heading 1.1\nText.\n"),
+			},
+		},
+		{
+			name:   "todo keywords and priorities",
+			source: "#+TODO: NEXT(n) | CANCELLED(c)\n* DONE [#A] Done\n* NEXT Next\n* CANCELLED\n* [#B] Priority\n* TODOS are kept\n",
+			want: Document{
+				Keywords: map[string]string{"todo": "NEXT(n) | CANCELLED(c)"},
+				Markdown: []byte("# Done\n# Next\n#\n# Priority\n# TODOS are kept\n"),
+			},
+		},
+		{
+			name:   "drawers",
+			source: "* Heading\n:PROPERTIES:\n:ID: 1234\n:END:\n:LOGBOOK:\n- State \"DONE\"\n:end:\nText.\n",
+			want: Document{
+				Keywords: map[string]string{},
+				Markdown: []byte("# Heading\nText.\n"),
+			},
+		},
+		{
+			name:   "inline markup",
+			source: "Some *bold*, /italic/ and +strike+ text with =verbatim= and ~code *not bold*~.\nA path a/b/c and 2*3*4.\n",
+			want: Document{
+				Keywords: map[string]string{},
+				Markdown: []byte("Some **bold**, *italic* and ~~strike~~ text with `verbatim` and `code *not bold*`.\nA path a/b/c and 2*3*4.\n"),
+			},
+		},
+		{
+			name:   "links",
+			source: "See [[https://example.com][the /example/]] and [[https://example.com]].\n[[file:images/cat.png]]\n[[./dog.JPG]]\n",
+			want: Document{
+				Keywords: map[string]string{},
+				Markdown: []byte("See [the *example*](https://example.com) and [https://example.com](https://example.com).\n![](images/cat.png)\n![](./dog.JPG)\n"),
+			},
+		},
+		{
+			name:   "lists",
+			source: "- one\n  + nested\n1) first\n2. second\n",
+			want: Document{
+				Keywords: map[string]string{},
+				Markdown: []byte("- one\n  - nested\n1. first\n2. second\n"),
+			},
+		},
+		{
+			name:   "table",
+			source: "| Country | Capital |\n|---------+---------|\n| France  | Paris   |\n",
+			want: Document{
+				Keywords: map[string]string{},
+				Markdown: []byte("| Country | Capital |\n|---|---|\n| France  | Paris   |\n"),
+			},
+		},
+		{
+			name:   "blocks",
+			source: "#+BEGIN_SRC go :results output\nfmt.Println(\"*not bold*\")\n#+END_SRC\n#+begin_quote\nA /quote/.\n#+end_quote\n#+BEGIN_EXAMPLE\n* not a heading\n#+END_EXAMPLE\n",
+			want: Document{
+				Keywords: map[string]string{},
+				Markdown: []byte("```go\nfmt.Println(\"*not bold*\")\n```\n> A *quote*.\n```\n* not a heading\n```\n"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert([]byte(tt.source))
+			assert.Equal(t, tt.want.Keywords, got.Keywords)
+			assert.Equal(t, string(tt.want.Markdown), string(got.Markdown))
+		})
+	}
+}
@@ -1,14 +1,23 @@
 package parser
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"unicode"
 
 	"github.com/adrg/frontmatter"
 
 	"github.com/leonhfr/mochi/internal/config"
+	"github.com/leonhfr/mochi/internal/parser/asciidoc"
+	"github.com/leonhfr/mochi/internal/parser/org"
 )
 
 // Reader represents the interface to read files.
@@ -16,7 +25,16 @@ type Reader interface {
 	Read(path string) (io.ReadCloser, error)
 }
 
-var extensions = []string{".md"}
+// Writer represents the interface to write files.
+type Writer interface {
+	Write(path string) (io.WriteCloser, error)
+}
+
+const (
+	markdownExtension = ".md"
+	orgExtension      = ".org"
+	asciidocExtension = ".adoc"
+)
 
 // Result contains the result.
 type Result struct {
@@ -26,11 +44,14 @@ type Result struct {
 
 // Card represents a card.
 type Card struct {
-	Content    string
-	Fields     map[string]string
-	TemplateID string
-	Path       string
-	Position   string
+	ID            string // persistent identifier embedded in the source, if any
+	Content       string
+	Fields        map[string]string
+	TemplateID    string
+	Path          string
+	Position      string
+	ReviewReverse bool
+	Audio         map[string]string // map[field id]name of the audio file, without extension
 }
 
 // Filename returns the filename.
@@ -47,21 +68,80 @@ func defaultParsers() map[string]cardParser {
 		"headings1": newHeadings(1),
 		"headings2": newHeadings(2),
 		"headings3": newHeadings(3),
-		"table":     newTable(),
+		"table":     newTable(newMarkdownTable()),
+		"cloze":     newCloze(newNote()),
+		"cloze1":    newCloze(newHeadings(1)),
+		"cloze2":    newCloze(newHeadings(2)),
+		"cloze3":    newCloze(newHeadings(3)),
+		"qa":        newQA(),
+		"list":      newList(1),
+		"list1":     newList(1),
+		"list2":     newList(2),
+		"list3":     newList(3),
+		"glossary":  newGlossary(config.GlossaryTemplate{}),
+	}
+}
+
+// DataExtensions returns the data file extensions.
+func DataExtensions() []string {
+	exts := make([]string, 0, len(dataDecoders()))
+	for ext := range dataDecoders() {
+		exts = append(exts, ext)
+	}
+	slices.Sort(exts)
+	return exts
+}
+
+// dataDecoders returns the table decoders of the data file extensions.
+func dataDecoders() map[string]tableDecoder {
+	return map[string]tableDecoder{
+		".csv":  newCSVTable(','),
+		".tsv":  newCSVTable('\t'),
+		".json": newRecordsTable(),
+		".yaml": newRecordsTable(),
+		".yml":  newRecordsTable(),
 	}
 }
 
+// registry represents the parsers of a file extension.
+type registry struct {
+	cardParser                       // used when no parser matches the name
+	parsers    map[string]cardParser // map[parser name]parser
+	custom     bool                  // whether the extension has been added by a custom parser
+}
+
+// get returns the parser matching the name or the default parser.
+func (r registry) get(name string) cardParser {
+	if cp, ok := r.parsers[name]; ok {
+		return cp
+	}
+	return r.cardParser
+}
+
 // Parser represents a parser.
 type Parser struct {
-	cardParser
-	parsers map[string]cardParser
+	registries map[string]registry // map[extension]registry
+	decoders   map[string]tableDecoder
+	tables     map[string]config.TableTemplate // also applied to the data files
+	writer     Writer                          // writes the missing card IDs, nil if disabled
+	mu         sync.Mutex                      // protects sources
+	sources    map[string][]byte               // map[path]source with the new card IDs, until written
 }
 
 // New returns a new parser.
+//
+// Markdown, org-mode and AsciiDoc files are parsed by the named parser.
 func New(options ...Option) (*Parser, error) {
+	notes := registry{cardParser: newNote(), parsers: defaultParsers()}
 	p := &Parser{
-		cardParser: newNote(),
-		parsers:    defaultParsers(),
+		registries: map[string]registry{
+			markdownExtension: notes,
+			orgExtension:      notes,
+			asciidocExtension: notes,
+		},
+		decoders: map[string]tableDecoder{},
+		tables:   map[string]config.TableTemplate{},
+		sources:  map[string][]byte{},
 	}
 	for _, option := range options {
 		if err := option(p); err != nil {
@@ -74,22 +154,211 @@ func New(options ...Option) (*Parser, error) {
 // Option represents an option for the parser.
 type Option func(*Parser) error
 
+// ConfigOptions returns the options declared in the config.
+//
+// The plugins are run from the workspace.
+func ConfigOptions(cfg *config.Config, workspace string) []Option {
+	options := []Option{
+		WithVocabulary(cfg.Vocabulary),
+		WithTables(cfg.Tables),
+		WithGlossaries(cfg.Glossaries),
+		WithPatterns(cfg.Patterns),
+		WithPlugins(workspace, cfg.Plugins),
+	}
+	if cfg.DataFiles() {
+		options = append(options, WithDataFiles())
+	}
+	return options
+}
+
 // WithVocabulary adds the vocabulary templates.
 func WithVocabulary(vocabulary map[string]config.VocabularyTemplate) Option {
 	return func(p *Parser) error {
+		parsers := p.registries[markdownExtension].parsers
 		for name, templateID := range vocabulary {
-			if _, ok := p.parsers[name]; ok {
+			if _, ok := parsers[name]; ok {
 				return fmt.Errorf("vocabulary template: cannot overwrite default parser %s", name)
 			}
-			p.parsers[name] = newVocabulary(templateID)
+			parsers[name] = newVocabulary(templateID)
 		}
 		return nil
 	}
 }
 
+// WithTables adds the table templates.
+//
+// They apply to the markdown tables as well as to the data files.
+func WithTables(tables map[string]config.TableTemplate) Option {
+	return func(p *Parser) error {
+		parsers := p.registries[markdownExtension].parsers
+		for name, template := range tables {
+			if _, ok := parsers[name]; ok {
+				return fmt.Errorf("table template: cannot overwrite parser %s", name)
+			}
+			parsers[name] = newTableTemplate(newMarkdownTable(), template)
+			p.tables[name] = template
+			for ext, decoder := range p.decoders {
+				p.registries[ext].parsers[name] = newTableTemplate(decoder, template)
+			}
+		}
+		return nil
+	}
+}
+
+// WithDataFiles enables the parsing of the data files (CSV, TSV, JSON and YAML).
+//
+// They are parsed as tables, filling the table template matching
+// the parser name if any. The extensions already handled by a custom
+// parser are left to it.
+func WithDataFiles() Option {
+	return func(p *Parser) error {
+		for ext, decoder := range dataDecoders() {
+			if _, ok := p.registries[ext]; ok {
+				continue
+			}
+			parsers := make(map[string]cardParser, len(p.tables))
+			for name, template := range p.tables {
+				parsers[name] = newTableTemplate(decoder, template)
+			}
+			p.decoders[ext] = decoder
+			p.registries[ext] = registry{cardParser: newTable(decoder), parsers: parsers}
+		}
+		return nil
+	}
+}
+
+// WithGlossaries adds the glossary templates.
+func WithGlossaries(glossaries map[string]config.GlossaryTemplate) Option {
+	return func(p *Parser) error {
+		parsers := p.registries[markdownExtension].parsers
+		for name, template := range glossaries {
+			if _, ok := parsers[name]; ok {
+				return fmt.Errorf("glossary template: cannot overwrite parser %s", name)
+			}
+			parsers[name] = newGlossary(template)
+		}
+		return nil
+	}
+}
+
+// WithPatterns adds the parsers declared in the config.
+func WithPatterns(patterns map[string]config.PatternParser) Option {
+	return func(p *Parser) error {
+		parsers := p.registries[markdownExtension].parsers
+		for name, pattern := range patterns {
+			if _, ok := parsers[name]; ok {
+				return fmt.Errorf("pattern parser: cannot overwrite parser %s", name)
+			}
+			cp, err := newPattern(pattern)
+			if err != nil {
+				return fmt.Errorf("pattern parser %s: %w", name, err)
+			}
+			parsers[name] = cp
+		}
+		return nil
+	}
+}
+
+// WithPlugins adds the parsers running external executables from dir.
+//
+// Each plugin is the default parser of its additional extensions.
+func WithPlugins(dir string, plugins map[string]config.PluginParser) Option {
+	return func(p *Parser) error {
+		for name, config := range plugins {
+			if err := p.register(name, newPlugin(name, dir, config), config.Extensions); err != nil {
+				return fmt.Errorf("plugin parser: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// CardParser is the interface that should be implemented by custom parsers.
+type CardParser interface {
+	Parse(path string, source []byte) (Result, error)
+}
+
+// WithParser adds a custom parser.
+//
+// Like the plugins, it is the default parser of its additional extensions.
+func WithParser(name string, cp CardParser, extensions ...string) Option {
+	return func(p *Parser) error {
+		if err := p.register(name, &custom{parser: cp}, extensions); err != nil {
+			return fmt.Errorf("custom parser: %w", err)
+		}
+		return nil
+	}
+}
+
+// custom adapts a CardParser to the cardParser interface.
+type custom struct {
+	parser CardParser
+}
+
+// parse implements the cardParser interface.
+func (c *custom) parse(path string, source []byte) (Result, error) {
+	return c.parser.Parse(path, source)
+}
+
+// register adds a named parser for the note files, and makes it the
+// default parser of the extensions no other parser handles yet.
+func (p *Parser) register(name string, cp cardParser, extensions []string) error {
+	parsers := p.registries[markdownExtension].parsers
+	if _, ok := parsers[name]; ok {
+		return fmt.Errorf("cannot overwrite parser %s", name)
+	}
+	parsers[name] = cp
+
+	for _, ext := range extensions {
+		r, ok := p.registries[ext]
+		if !ok {
+			p.registries[ext] = registry{cardParser: cp, parsers: map[string]cardParser{name: cp}, custom: true}
+			continue
+		}
+		if r.custom {
+			return fmt.Errorf("parser %s: extension %s already handled by another parser", name, ext)
+		}
+		r.parsers[name] = cp
+	}
+	return nil
+}
+
+// WithCardIDs enables the insertion of persistent card IDs in the source files.
+//
+// Each card without an ID is given a new one when parsed, written back
+// to its source file as an HTML comment by WriteCardIDs.
+func WithCardIDs(w Writer) Option {
+	return func(p *Parser) error {
+		p.writer = w
+		return nil
+	}
+}
+
 // Parse converts a source file into cards.
 func (p *Parser) Parse(reader Reader, parser, path string) (Result, error) {
-	content, matter, err := parseFrontmatter(reader, path)
+	ext := filepath.Ext(path)
+	r, ok := p.registries[ext]
+	if !ok {
+		return Result{}, fmt.Errorf("parse %s: unsupported extension %s", path, ext)
+	}
+
+	var raw, content []byte
+	var matter matter
+	var err error
+	switch ext {
+	case markdownExtension:
+		raw, content, matter, err = parseFrontmatter(reader, path)
+	case orgExtension:
+		content, matter, err = parseOrg(reader, path)
+	case asciidocExtension:
+		content, matter, err = parseAsciiDoc(reader, path)
+	default:
+		source, err := readFile(reader, path)
+		if err != nil {
+			return Result{}, err
+		}
+		return r.get(parser).parse(path, source)
+	}
 	if err != nil {
 		return Result{}, err
 	}
@@ -102,11 +371,104 @@ func (p *Parser) Parse(reader Reader, parser, path string) (Result, error) {
 		parser = matter.Parser
 	}
 
-	if cp, ok := p.parsers[parser]; ok {
-		return cp.parse(path, content)
+	cp := r.get(parser)
+
+	// the card IDs are only written back to markdown sources
+	if ext == markdownExtension {
+		if content, err = p.addCardIDs(cp, path, raw, content); err != nil {
+			return Result{}, err
+		}
+	}
+
+	return cp.parse(path, content)
+}
+
+// addCardIDs gives an ID to the cards of the source that do not have one,
+// and records the updated source until it is written by WriteCardIDs.
+func (p *Parser) addCardIDs(cp cardParser, path string, raw, content []byte) ([]byte, error) {
+	id, ok := cp.(identifier)
+	if p.writer == nil || !ok {
+		return content, nil
+	}
+
+	updated, ok, err := insertCardIDs(content, id.blocks(content))
+	if err != nil || !ok {
+		return content, err
+	}
+
+	matter := raw[:len(raw)-len(content)]
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sources[path] = append(matter[:len(matter):len(matter)], updated...)
+	return updated, nil
+}
+
+// WriteCardIDs writes the card IDs given during parsing back to
+// their source files, once the cards have been synced.
+//
+// The sources for which skip returns true are left untouched.
+func (p *Parser) WriteCardIDs(skip func(path string) bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	paths := slices.Sorted(maps.Keys(p.sources))
+	for _, path := range paths {
+		if skip(path) {
+			continue
+		}
+
+		if err := writeFile(p.writer, path, p.sources[path]); err != nil {
+			return err
+		}
+	}
+
+	clear(p.sources)
+	return nil
+}
+
+// Source returns the source of the file at path with the card IDs
+// given during parsing, until they are written.
+func (p *Parser) Source(path string) ([]byte, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	source, ok := p.sources[path]
+	return source, ok
+}
+
+// Fingerprint returns a hash of the plugin executables, so that the files
+// are parsed again when a plugin is updated.
+//
+// All the plugins are covered, since the front matter may select any of them.
+func (p *Parser) Fingerprint() string {
+	plugins := map[string]*plugin{}
+	for _, r := range p.registries {
+		for name, cp := range r.parsers {
+			if cp, ok := cp.(*plugin); ok {
+				plugins[name] = cp
+			}
+		}
+	}
+
+	h := sha256.New()
+	for _, name := range slices.Sorted(maps.Keys(plugins)) {
+		fmt.Fprintf(h, "plugin:%s:%s\x00", name, plugins[name].fingerprint())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeFile(writer Writer, path string, source []byte) error {
+	w, err := writer.Write(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(source); err != nil {
+		w.Close()
+		return err
 	}
 
-	return p.parse(path, content)
+	return w.Close()
 }
 
 type matter struct {
@@ -114,20 +476,64 @@ type matter struct {
 	Skip   bool   `yaml:"mochi-skip"`
 }
 
-func parseFrontmatter(reader Reader, path string) ([]byte, matter, error) {
-	bytes, err := reader.Read(path)
+func readFile(reader Reader, path string) ([]byte, error) {
+	r, err := reader.Read(path)
 	if err != nil {
-		return nil, matter{}, err
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+func parseFrontmatter(reader Reader, path string) ([]byte, []byte, matter, error) {
+	raw, err := readFile(reader, path)
+	if err != nil {
+		return nil, nil, matter{}, err
 	}
-	defer bytes.Close()
 
 	var fm matter
-	content, err := frontmatter.Parse(bytes, &fm)
+	content, err := frontmatter.Parse(bytes.NewReader(raw), &fm)
+	if err != nil {
+		return nil, nil, matter{}, err
+	}
+
+	return raw, content, fm, nil
+}
+
+// parseOrg converts an org-mode source to markdown.
+//
+// The #+MOCHI-PARSER and #+MOCHI-SKIP keywords act as frontmatter.
+func parseOrg(reader Reader, path string) ([]byte, matter, error) {
+	raw, err := readFile(reader, path)
 	if err != nil {
 		return nil, matter{}, err
 	}
 
-	return content, fm, nil
+	doc := org.Convert(raw)
+	return doc.Markdown, keywordMatter(doc.Keywords), nil
+}
+
+// parseAsciiDoc converts an AsciiDoc source to markdown.
+//
+// The :mochi-parser: and :mochi-skip: attributes act as frontmatter.
+func parseAsciiDoc(reader Reader, path string) ([]byte, matter, error) {
+	raw, err := readFile(reader, path)
+	if err != nil {
+		return nil, matter{}, err
+	}
+
+	doc := asciidoc.Convert(raw)
+	return doc.Markdown, keywordMatter(doc.Attributes), nil
+}
+
+// keywordMatter returns the frontmatter set by the keywords of a converted document.
+func keywordMatter(keywords map[string]string) matter {
+	skip := strings.ToLower(keywords["mochi-skip"])
+	return matter{
+		Parser: keywords["mochi-parser"],
+		Skip:   skip == "t" || skip == "true" || skip == "yes",
+	}
 }
 
 // Names returns the list of allowed parser names.
@@ -142,7 +548,12 @@ func Names() []string {
 
 // Extensions returns the list of supported extensions.
 func (p *Parser) Extensions() []string {
-	return extensions
+	exts := make([]string, 0, len(p.registries))
+	for ext := range p.registries {
+		exts = append(exts, ext)
+	}
+	slices.Sort(exts)
+	return exts
 }
 
 func getFilename(path string) string {
@@ -151,16 +562,15 @@ func getFilename(path string) string {
 
 func getNameFromPath(path string) string {
 	base := filepath.Base(path)
-	for _, ext := range extensions {
-		base = strings.TrimSuffix(base, ext)
-	}
-	return base
+	return strings.TrimSuffix(base, filepath.Ext(base))
 }
 
+// sanitizePosition keeps the letters and digits of a position, including
+// the non-ASCII ones so that terms such as Ästhetik or 日本 keep their order.
 func sanitizePosition(position string) string {
 	runes := make([]rune, 0, len(position))
 	for _, r := range position {
-		if ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
 			runes = append(runes, r)
 		}
 	}
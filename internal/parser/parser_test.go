@@ -65,10 +65,14 @@ func Test_Parser_Convert(t *testing.T) {
 			p1 := newMockCardParser(tt.parser1)
 			p2 := newMockCardParser(tt.parser2)
 			parser := &Parser{
-				cardParser: p0,
-				parsers: map[string]cardParser{
-					"parser1": p1,
-					"parser2": p2,
+				registries: map[string]registry{
+					markdownExtension: {
+						cardParser: p0,
+						parsers: map[string]cardParser{
+							"parser1": p1,
+							"parser2": p2,
+						},
+					},
 				},
 			}
 
@@ -133,3 +137,139 @@ func (m *mockCardParser) parse(path string, source []byte) (Result, error) {
 	args := m.Called(path, source)
 	return args.Get(0).(Result), args.Error(1)
 }
+
+func Test_Parser_Parse_org(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser string
+		source string
+		want   Result
+	}{
+		{
+			name:   "skip",
+			parser: "note",
+			source: "#+MOCHI-SKIP: t\n* Heading\n",
+		},
+		{
+			name:   "note",
+			parser: "note",
+			source: "#+TITLE: Notes\nSome *bold* text.\n[[file:cat.png]]\n",
+			want: Result{Cards: []Card{
+				{
+					Content:  "# Notes\n\nSome **bold** text.\n![](cat.png)\n",
+					Fields:   nameFields("Notes"),
+					Path:     "/org/Notes.org",
+					Position: "Notes",
+				},
+			}},
+		},
+		{
+			name:   "keyword overwrites parser",
+			parser: "note",
+			source: "#+MOCHI-PARSER: headings\n* Heading 1\nContent 1.\n* Heading 2\nContent 2.\n",
+			want: Result{Deck: "Notes", Cards: []Card{
+				{
+					Content:  "# Heading 1\n\n<details><summary>Headings</summary>Heading 1</details>\n\nContent 1.\n",
+					Fields:   nameFields("Notes > Heading 1"),
+					Path:     "/org/Notes.org",
+					Position: "Notesorg0000",
+				},
+				{
+					Content:  "# Heading 2\n\n<details><summary>Headings</summary>Heading 2</details>\n\nContent 2.\n",
+					Fields:   nameFields("Notes > Heading 2"),
+					Path:     "/org/Notes.org",
+					Position: "Notesorg0001",
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New()
+			assert.NoError(t, err)
+
+			r := newMockReader([]readCall{{path: "/org/Notes.org", text: tt.source}})
+			got, err := p.Parse(r, tt.parser, "/org/Notes.org")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_Parser_Parse_asciidoc(t *testing.T) {
+	tests := []struct {
+		name   string
+		parser string
+		source string
+		want   Result
+	}{
+		{
+			name:   "skip",
+			parser: "note",
+			source: ":mochi-skip: true\n== Section\n",
+		},
+		{
+			name:   "note",
+			parser: "note",
+			source: "= Notes\nSome *bold* text.\nimage::cat.png[]\n",
+			want: Result{Cards: []Card{
+				{
+					Content:  "# Notes\n\nSome **bold** text.\n![](cat.png)\n",
+					Fields:   nameFields("Notes"),
+					Path:     "/adoc/Notes.adoc",
+					Position: "Notes",
+				},
+			}},
+		},
+		{
+			name:   "attribute overwrites parser",
+			parser: "note",
+			source: ":mochi-parser: headings\n== Section 1\nContent 1.\n== Section 2\nContent 2.\n",
+			want: Result{Deck: "Notes", Cards: []Card{
+				{
+					Content:  "# Section 1\n\n<details><summary>Headings</summary>Section 1</details>\n\nContent 1.\n",
+					Fields:   nameFields("Notes > Section 1"),
+					Path:     "/adoc/Notes.adoc",
+					Position: "Notesadoc0000",
+				},
+				{
+					Content:  "# Section 2\n\n<details><summary>Headings</summary>Section 2</details>\n\nContent 2.\n",
+					Fields:   nameFields("Notes > Section 2"),
+					Path:     "/adoc/Notes.adoc",
+					Position: "Notesadoc0001",
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New()
+			assert.NoError(t, err)
+
+			r := newMockReader([]readCall{{path: "/adoc/Notes.adoc", text: tt.source}})
+			got, err := p.Parse(r, tt.parser, "/adoc/Notes.adoc")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_sanitizePosition(t *testing.T) {
+	tests := []struct {
+		position string
+		want     string
+	}{
+		{"Lorem ipsum", "Loremipsum"},
+		{"Notes.md0001", "Notesmd0001"},
+		{"Café crème", "Cafécrème"},
+		{"日本 (にほん)", "日本にほん"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.position, func(t *testing.T) {
+			assert.Equal(t, tt.want, sanitizePosition(tt.position))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/leonhfr/mochi/internal/config"
+)
+
+const patternContentGroup = "content"
+
+// pattern represents a parser declared in the config.
+//
+// Each block, or each match of the pattern, returns a separate card.
+// The named groups of the pattern fill the name, the sides and the fields.
+type pattern struct {
+	regexp *regexp.Regexp // nil when the whole block is captured
+	config config.PatternParser
+}
+
+// newPattern returns a new pattern parser.
+//
+// It returns an error if the pattern is invalid or if a group is missing.
+func newPattern(config config.PatternParser) (*pattern, error) {
+	p := &pattern{config: config}
+	groups := []string{patternContentGroup}
+	if config.Pattern != "" {
+		re, err := regexp.Compile(config.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		p.regexp = re
+		groups = re.SubexpNames()
+	}
+
+	for _, group := range p.groups() {
+		if !slices.Contains(groups, group) {
+			return nil, fmt.Errorf("group %s not found", group)
+		}
+	}
+
+	if len(config.Sides) == 0 && config.TemplateID == "" && p.regexp != nil {
+		return nil, fmt.Errorf("either sides or templateID is required")
+	}
+
+	return p, nil
+}
+
+// parse implements the cardParser interface.
+func (p *pattern) parse(path string, source []byte) (Result, error) {
+	cards := []Card{}
+	for _, match := range p.matches(string(source)) {
+		if card, ok := p.card(match, path, len(cards)); ok {
+			cards = append(cards, card)
+		}
+	}
+
+	return Result{
+		Deck:  getNameFromPath(path),
+		Cards: cards,
+	}, nil
+}
+
+// matches returns the named groups of each match.
+func (p *pattern) matches(source string) []map[string]string {
+	blocks := []string{source}
+	if p.config.Separator != "" {
+		blocks = strings.Split(source, p.config.Separator)
+	}
+
+	var matches []map[string]string
+	for _, block := range blocks {
+		if p.regexp == nil {
+			matches = append(matches, map[string]string{patternContentGroup: block})
+			continue
+		}
+
+		n := -1
+		if p.config.Separator != "" {
+			n = 1
+		}
+		for _, submatches := range p.regexp.FindAllStringSubmatch(block, n) {
+			match := map[string]string{}
+			for i, name := range p.regexp.SubexpNames() {
+				if name != "" {
+					match[name] = submatches[i]
+				}
+			}
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+func (p *pattern) card(match map[string]string, path string, index int) (Card, bool) {
+	sides := p.config.Sides
+	if len(sides) == 0 && p.regexp == nil {
+		sides = []string{patternContentGroup}
+	}
+
+	var content []string
+	for _, side := range sides {
+		if value := strings.TrimSpace(match[side]); value != "" {
+			content = append(content, value)
+		}
+	}
+
+	fields := map[string]string{}
+	for group, fieldID := range p.config.Fields {
+		if value := strings.TrimSpace(match[group]); value != "" {
+			fields[fieldID] = value
+		}
+	}
+
+	name := strings.TrimSpace(match[p.config.Name])
+	if name == "" && len(content) > 0 {
+		name, _, _ = strings.Cut(content[0], "\n")
+	}
+	if name == "" || (len(content) == 0 && len(fields) == 0) {
+		return Card{}, false
+	}
+	fields["name"] = name
+
+	card := Card{
+		Fields:     fields,
+		TemplateID: p.config.TemplateID,
+		Path:       path,
+		Position:   sanitizePosition(fmt.Sprintf("%s%04d", getFilename(path), index)),
+	}
+	if len(content) > 0 {
+		card.Content = strings.Join(content, "\n\n---\n\n") + "\n"
+	}
+	return card, true
+}
+
+// groups returns the groups referenced by the config.
+func (p *pattern) groups() []string {
+	var groups []string
+	if p.config.Name != "" {
+		groups = append(groups, p.config.Name)
+	}
+	groups = append(groups, p.config.Sides...)
+	for group := range p.config.Fields {
+		groups = append(groups, group)
+	}
+	return groups
+}
@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonhfr/mochi/internal/config"
+)
+
+func Test_newPattern(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.PatternParser
+		err    bool
+	}{
+		{
+			name:   "separator",
+			config: config.PatternParser{Separator: "\n%%\n"},
+		},
+		{
+			name:   "pattern",
+			config: config.PatternParser{Pattern: `(?P<front>.+) => (?P<back>.+)`, Sides: []string{"front", "back"}},
+		},
+		{
+			name:   "invalid pattern",
+			config: config.PatternParser{Pattern: `(?P<front>.+`, Sides: []string{"front"}},
+			err:    true,
+		},
+		{
+			name:   "missing group",
+			config: config.PatternParser{Pattern: `(?P<front>.+)`, Sides: []string{"front", "back"}},
+			err:    true,
+		},
+		{
+			name:   "missing sides and template",
+			config: config.PatternParser{Pattern: `(?P<front>.+)`, Name: "front"},
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newPattern(tt.config)
+			if tt.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_pattern_parse(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.PatternParser
+		path   string
+		source string
+		want   Result
+	}{
+		{
+			name:   "separator",
+			config: config.PatternParser{Separator: "\n%%\n"},
+			path:   "/Cards.md",
+			source: "First card\nwith content\n%%\n\n%%\nSecond card\n",
+			want: Result{Deck: "Cards", Cards: []Card{
+				{
+					Content:  "First card\nwith content\n",
+					Fields:   nameFields("First card"),
+					Path:     "/Cards.md",
+					Position: "Cardsmd0000",
+				},
+				{
+					Content:  "Second card\n",
+					Fields:   nameFields("Second card"),
+					Path:     "/Cards.md",
+					Position: "Cardsmd0001",
+				},
+			}},
+		},
+		{
+			name: "pattern",
+			config: config.PatternParser{
+				Pattern: `(?m)^(?P<front>.+?) => (?P<back>.+)$`,
+				Sides:   []string{"front", "back"},
+			},
+			path:   "/Cards.md",
+			source: "# Title\n\nHund => Dog\nSome text.\nKatze => Cat\n",
+			want: Result{Deck: "Cards", Cards: []Card{
+				{
+					Content:  "Hund\n\n---\n\nDog\n",
+					Fields:   nameFields("Hund"),
+					Path:     "/Cards.md",
+					Position: "Cardsmd0000",
+				},
+				{
+					Content:  "Katze\n\n---\n\nCat\n",
+					Fields:   nameFields("Katze"),
+					Path:     "/Cards.md",
+					Position: "Cardsmd0001",
+				},
+			}},
+		},
+		{
+			name: "separator and template",
+			config: config.PatternParser{
+				Separator:  "\n---\n",
+				Pattern:    `(?s)Word: (?P<word>[^\n]+)\nMeaning: (?P<meaning>.+)`,
+				Name:       "word",
+				Fields:     map[string]string{"word": "WORD_ID", "meaning": "MEANING_ID"},
+				TemplateID: "TEMPLATE_ID",
+			},
+			path:   "/Words.md",
+			source: "Word: Hund\nMeaning: dog\n---\nNo match.\n---\nWord: Katze\nMeaning: cat\n",
+			want: Result{Deck: "Words", Cards: []Card{
+				{
+					Fields:     map[string]string{"name": "Hund", "WORD_ID": "Hund", "MEANING_ID": "dog"},
+					TemplateID: "TEMPLATE_ID",
+					Path:       "/Words.md",
+					Position:   "Wordsmd0000",
+				},
+				{
+					Fields:     map[string]string{"name": "Katze", "WORD_ID": "Katze", "MEANING_ID": "cat"},
+					TemplateID: "TEMPLATE_ID",
+					Path:       "/Words.md",
+					Position:   "Wordsmd0001",
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newPattern(tt.config)
+			require.NoError(t, err)
+
+			got, err := p.parse(tt.path, []byte(tt.source))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
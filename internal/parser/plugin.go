@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leonhfr/mochi/internal/config"
+)
+
+// pluginWaitDelay is the time given to the executable's output
+// to be closed once the executable has been killed.
+const pluginWaitDelay = 200 * time.Millisecond
+
+// plugin represents a parser running an external executable.
+//
+// The executable receives a pluginRequest as JSON on its stdin and
+// must write a pluginResponse as JSON on its stdout before the timeout.
+// A non-zero exit status fails the parsing, its stderr is reported.
+type plugin struct {
+	name    string
+	dir     string // working directory of the executable
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+// newPlugin returns a new plugin parser.
+func newPlugin(name, dir string, config config.PluginParser) *plugin {
+	return &plugin{
+		name:    name,
+		dir:     dir,
+		command: pluginCommand(dir, config.Command),
+		args:    config.Args,
+		timeout: config.Timeout,
+	}
+}
+
+// pluginCommand returns the command to run.
+//
+// A name without path separator is looked up in the PATH first, then in dir.
+// Relative paths are resolved from dir, the working directory of the executable.
+func pluginCommand(dir, command string) string {
+	if filepath.Base(command) != command {
+		return command
+	}
+	if _, err := exec.LookPath(command); err == nil {
+		return command
+	}
+	return filepath.Join(dir, command)
+}
+
+// fingerprint returns the path, size and modification time of the executable.
+func (p *plugin) fingerprint() string {
+	path := p.command
+	if filepath.Base(path) == path {
+		if found, err := exec.LookPath(path); err == nil {
+			path = found
+		}
+	} else if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Sprintf("%s:%v", path, err)
+	}
+	return fmt.Sprintf("%s:%d:%d", path, info.Size(), info.ModTime().UnixNano())
+}
+
+type pluginRequest struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+type pluginResponse struct {
+	Deck  string       `json:"deck"`
+	Cards []pluginCard `json:"cards"`
+}
+
+type pluginCard struct {
+	ID            string            `json:"id"`
+	Content       string            `json:"content"`
+	Fields        map[string]string `json:"fields"`
+	TemplateID    string            `json:"templateID"`
+	Position      string            `json:"position"`
+	ReviewReverse bool              `json:"reviewReverse"`
+}
+
+// parse implements the cardParser interface.
+func (p *plugin) parse(path string, source []byte) (Result, error) {
+	output, err := p.run(path, source)
+	if err != nil {
+		return Result{}, fmt.Errorf("plugin %s: %s: %w", p.name, path, err)
+	}
+
+	var res pluginResponse
+	if err := json.Unmarshal(output, &res); err != nil {
+		return Result{}, fmt.Errorf("plugin %s: %s: invalid output: %w", p.name, path, err)
+	}
+
+	cards := make([]Card, 0, len(res.Cards))
+	for i, card := range res.Cards {
+		cards = append(cards, newPluginCard(card, path, i))
+	}
+
+	return Result{
+		Deck:  res.Deck,
+		Cards: cards,
+	}, nil
+}
+
+func (p *plugin) run(path string, source []byte) ([]byte, error) {
+	input, err := json.Marshal(pluginRequest{Path: path, Content: string(source)})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.Dir = p.dir
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = pluginWaitDelay
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out after %s", p.timeout)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func newPluginCard(card pluginCard, path string, index int) Card {
+	position := card.Position
+	if position == "" {
+		position = fmt.Sprintf("%s%04d", getFilename(path), index)
+	}
+
+	fields := card.Fields
+	if fields == nil {
+		fields = map[string]string{}
+	}
+
+	return Card{
+		ID:            card.ID,
+		Content:       card.Content,
+		Fields:        fields,
+		TemplateID:    card.TemplateID,
+		Path:          path,
+		Position:      sanitizePosition(position),
+		ReviewReverse: card.ReviewReverse,
+	}
+}
@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonhfr/mochi/internal/config"
+)
+
+func Test_plugin_parse(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   Result
+		err    string
+	}{
+		{
+			name:   "cards",
+			script: `cat > /dev/null; echo '{"deck": "Games", "cards": [{"content": "1. e4", "fields": {"name": "Opening"}, "templateID": "TEMPLATE_ID", "reviewReverse": true}, {"id": "abc", "content": "2. d4", "position": "b-2"}]}'`,
+			want: Result{Deck: "Games", Cards: []Card{
+				{
+					Content:       "1. e4",
+					Fields:        nameFields("Opening"),
+					TemplateID:    "TEMPLATE_ID",
+					Path:          "/chess/Game.pgn",
+					Position:      "Gamepgn0000",
+					ReviewReverse: true,
+				},
+				{
+					ID:       "abc",
+					Content:  "2. d4",
+					Fields:   map[string]string{},
+					Path:     "/chess/Game.pgn",
+					Position: "b2",
+				},
+			}},
+		},
+		{
+			name:   "input and exit status",
+			script: `cat >&2; exit 3`,
+			err:    `plugin chess: /chess/Game.pgn: exit status 3: {"path":"/chess/Game.pgn","content":"1. e4 e5"}`,
+		},
+		{
+			name:   "invalid output",
+			script: `cat > /dev/null; echo 'not json'`,
+			err:    "plugin chess: /chess/Game.pgn: invalid output: invalid character 'o' in literal null (expecting 'u')",
+		},
+		{
+			name:   "timeout",
+			script: `sleep 5`,
+			err:    "plugin chess: /chess/Game.pgn: timed out after 50ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPlugin("chess", t.TempDir(), config.PluginParser{
+				Command: "sh",
+				Args:    []string{"-c", tt.script},
+				Timeout: 50 * time.Millisecond,
+			})
+
+			got, err := p.parse("/chess/Game.pgn", []byte("1. e4 e5"))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_plugin_parse_workspaceCommand(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat > /dev/null\necho '{\"cards\": [{\"content\": \"1. e4\"}]}'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mochi-chess-plugin"), []byte(script), 0o755))
+
+	p := newPlugin("chess", dir, config.PluginParser{
+		Command: "mochi-chess-plugin",
+		Timeout: time.Second,
+	})
+
+	got, err := p.parse("/chess/Game.pgn", []byte("1. e4 e5"))
+	require.NoError(t, err)
+	assert.Equal(t, Result{Cards: []Card{
+		{
+			Content:  "1. e4",
+			Fields:   map[string]string{},
+			Path:     "/chess/Game.pgn",
+			Position: "Gamepgn0000",
+		},
+	}}, got)
+}
+
+func Test_Parser_Fingerprint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mochi-chess-plugin")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755))
+
+	p, err := New(WithPlugins(dir, map[string]config.PluginParser{
+		"chess": {Command: "mochi-chess-plugin", Extensions: []string{".pgn"}},
+	}))
+	require.NoError(t, err)
+	fingerprint := p.Fingerprint()
+	assert.Equal(t, fingerprint, p.Fingerprint())
+
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), 0o755))
+	assert.NotEqual(t, fingerprint, p.Fingerprint(), "updated executable")
+
+	none, err := New()
+	require.NoError(t, err)
+	assert.NotEqual(t, fingerprint, none.Fingerprint(), "no plugin")
+}
+
+func Test_pluginCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{name: "in the PATH", command: "sh", want: "sh"},
+		{name: "not in the PATH", command: "mochi-chess-plugin", want: "/workspace/mochi-chess-plugin"},
+		{name: "relative path", command: "plugins/chess", want: "plugins/chess"},
+		{name: "absolute path", command: "/usr/bin/chess", want: "/usr/bin/chess"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, pluginCommand("/workspace", tt.command))
+		})
+	}
+}
+
+func Test_WithPlugins(t *testing.T) {
+	p, err := New(WithPlugins("/workspace", map[string]config.PluginParser{
+		"chess": {Command: "chess", Extensions: []string{".pgn", ".md"}},
+	}))
+	require.NoError(t, err)
+
+	assert.Contains(t, p.Extensions(), ".pgn")
+	assert.IsType(t, &plugin{}, p.registries[".pgn"].get("other"))
+	assert.IsType(t, &plugin{}, p.registries[markdownExtension].get("chess"))
+	assert.IsType(t, &note{}, p.registries[markdownExtension].get("other"))
+
+	_, err = New(WithPlugins("/workspace", map[string]config.PluginParser{
+		"note": {Command: "note"},
+	}))
+	assert.Error(t, err)
+}
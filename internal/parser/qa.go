@@ -0,0 +1,321 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	qaQuestionPrefix  = "Q:"
+	qaAnswerPrefix    = "A:"
+	qaInlineSeparator = "::"
+	qaInlineReverse   = ":::"
+	qaBlockSeparator  = "?"
+	qaBlockReverse    = "??"
+)
+
+// qa represents a question/answer parser.
+//
+// Each question/answer pair returns a separate card, whose sides
+// are separated by mochi's side separator. The pairs may be written as:
+//
+//	Q: question
+//	A: answer
+//
+//	question :: answer
+//	question ::: reversed answer
+//
+//	question
+//	?
+//	answer
+//
+// where a ?? separator also creates a reversed card.
+// Blocks are separated by blank lines. The card ID of a pair
+// is written at the end of its first line.
+type qa struct{}
+
+// newQA returns a new question/answer parser.
+func newQA() *qa {
+	return &qa{}
+}
+
+// parse implements the cardParser interface.
+func (q *qa) parse(path string, source []byte) (Result, error) {
+	cards := []Card{}
+	for _, block := range getQABlocks(string(source)) {
+		for _, pair := range parseQABlock(block.lines) {
+			cards = append(cards, newQACard(pair, path, len(cards)))
+		}
+	}
+
+	return Result{
+		Deck:  getNameFromPath(path),
+		Cards: cards,
+	}, nil
+}
+
+// blocks implements the identifier interface.
+//
+// The card ID is inserted at the end of the first line of the pair,
+// or on its own line before a pair starting with a fenced code block.
+func (q *qa) blocks(source []byte) []block {
+	var offsets []int // offset of the start of each line
+	for offset := 0; offset <= len(source); {
+		offsets = append(offsets, offset)
+		index := bytes.IndexByte(source[offset:], '\n')
+		if index < 0 {
+			break
+		}
+		offset += index + 1
+	}
+	lineEnd := func(line int) int {
+		if line+1 < len(offsets) {
+			return offsets[line+1] - 1
+		}
+		return len(source)
+	}
+
+	var blocks []block
+	for _, qaBlock := range getQABlocks(string(source)) {
+		for _, pair := range parseQABlock(qaBlock.lines) {
+			first, last := qaBlock.start+pair.first, qaBlock.start+pair.last
+			b := block{start: offsets[first], stop: lineEnd(last), insert: lineEnd(first), prefix: " "}
+			if fenceMarker(strings.TrimSpace(qaBlock.lines[pair.first])) != "" {
+				b.insert, b.prefix, b.suffix = offsets[first], "", "\n"
+			}
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+type qaPair struct {
+	id       string
+	question string
+	answer   string
+	reverse  bool
+	first    int // index of the first line of the pair in its block
+	last     int // index of the last line of the pair in its block
+}
+
+// qaBlock represents lines of the source not separated by blank lines.
+type qaBlock struct {
+	lines []string
+	start int // index of the first line in the source
+}
+
+// getQABlocks splits the source into blocks separated by blank lines.
+//
+// Fenced code blocks are never split.
+func getQABlocks(source string) []qaBlock {
+	var blocks []qaBlock
+	var current qaBlock
+	var fence string
+	for i, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" && trimmed == "" {
+			if len(current.lines) > 0 {
+				blocks = append(blocks, current)
+			}
+			current = qaBlock{}
+			continue
+		}
+
+		if marker := fenceMarker(trimmed); fence == "" && marker != "" {
+			fence = marker
+		} else if fence != "" && strings.HasPrefix(trimmed, fence) {
+			fence = ""
+		}
+		if len(current.lines) == 0 {
+			current.start = i
+		}
+		current.lines = append(current.lines, line)
+	}
+	if len(current.lines) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
+
+// getFencedLines returns whether each line belongs to a fenced code block.
+func getFencedLines(lines []string) []bool {
+	fenced := make([]bool, len(lines))
+	var fence string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarker(trimmed); fence == "" && marker != "" {
+			fence = marker
+		} else if fence != "" && strings.HasPrefix(trimmed, fence) {
+			fence = ""
+			fenced[i] = true
+		}
+		fenced[i] = fenced[i] || fence != ""
+	}
+	return fenced
+}
+
+func fenceMarker(line string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+func parseQABlock(lines []string) []qaPair {
+	fenced := getFencedLines(lines)
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+
+		switch strings.TrimSpace(line) {
+		case qaBlockSeparator, qaBlockReverse:
+			pair, ok := newQAPair(lines[:i], lines[i+1:], strings.TrimSpace(line) == qaBlockReverse)
+			if !ok {
+				return nil
+			}
+			pair.first, pair.last = 0, len(lines)-1
+			return []qaPair{pair}
+		}
+	}
+
+	if strings.HasPrefix(lines[0], qaQuestionPrefix) {
+		return parseQALines(lines)
+	}
+
+	var pairs []qaPair
+	for i, line := range lines {
+		if fenced[i] {
+			continue
+		}
+
+		if pair, ok := parseQAInline(line); ok {
+			pair.first, pair.last = i, i
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// parseQALines parses the Q: and A: prefixed pairs.
+//
+// Each prefix spans until the next prefix.
+func parseQALines(lines []string) []qaPair {
+	var pairs []qaPair
+	var question, answer []string
+	inAnswer, first := false, 0
+
+	flush := func(last int) {
+		if pair, ok := newQAPair(question, answer, false); ok {
+			pair.first, pair.last = first, last
+			pairs = append(pairs, pair)
+		}
+		question, answer, inAnswer = nil, nil, false
+	}
+
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, qaQuestionPrefix):
+			flush(i - 1)
+			first = i
+			question = append(question, strings.TrimPrefix(line, qaQuestionPrefix))
+		case strings.HasPrefix(line, qaAnswerPrefix) && !inAnswer:
+			inAnswer = true
+			answer = append(answer, strings.TrimPrefix(line, qaAnswerPrefix))
+		case inAnswer:
+			answer = append(answer, line)
+		default:
+			question = append(question, line)
+		}
+	}
+	flush(len(lines) - 1)
+
+	return pairs
+}
+
+func parseQAInline(line string) (qaPair, bool) {
+	if question, answer, ok := cutOutsideCode(line, qaInlineReverse); ok {
+		return newQAPair([]string{question}, []string{answer}, true)
+	}
+	if question, answer, ok := cutOutsideCode(line, qaInlineSeparator); ok {
+		return newQAPair([]string{question}, []string{answer}, false)
+	}
+	return qaPair{}, false
+}
+
+// cutOutsideCode slices the line around the first instance of the
+// separator outside the code spans, like strings.Cut.
+func cutOutsideCode(line, sep string) (string, string, bool) {
+	for i := 0; i < len(line); {
+		if line[i] == '`' {
+			n := backtickRun(line[i:])
+			// unmatched backticks are literal
+			if end := closingBackticks(line[i+n:], n); end >= 0 {
+				i += n + end
+			}
+			i += n
+			continue
+		}
+
+		if strings.HasPrefix(line[i:], sep) {
+			return line[:i], line[i+len(sep):], true
+		}
+		i++
+	}
+	return line, "", false
+}
+
+// closingBackticks returns the index of the first run of exactly n backticks, or -1.
+func closingBackticks(s string, n int) int {
+	for i := 0; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+
+		run := backtickRun(s[i:])
+		if run == n {
+			return i
+		}
+		i += run
+	}
+	return -1
+}
+
+func backtickRun(s string) int {
+	return len(s) - len(strings.TrimLeft(s, "`"))
+}
+
+// newQAPair returns the pair of the question and answer lines,
+// and the card ID found in either of them.
+func newQAPair(question, answer []string, reverse bool) (qaPair, bool) {
+	id, q := extractCardID([]byte(strings.Join(question, "\n")))
+	a := []byte(strings.Join(answer, "\n"))
+	if id == "" {
+		id, a = extractCardID(a)
+	}
+	pair := qaPair{
+		id:       id,
+		question: strings.TrimSpace(string(q)),
+		answer:   strings.TrimSpace(string(a)),
+		reverse:  reverse,
+	}
+	return pair, pair.question != "" && pair.answer != ""
+}
+
+func newQACard(pair qaPair, path string, index int) Card {
+	filename := getFilename(path)
+	position := fmt.Sprintf("%s%04d", filename, index)
+	name, _, _ := strings.Cut(pair.question, "\n")
+	return Card{
+		ID:            pair.id,
+		Content:       fmt.Sprintf("%s\n\n---\n\n%s\n", pair.question, pair.answer),
+		Fields:        nameFields(name),
+		Path:          path,
+		Position:      sanitizePosition(position),
+		ReviewReverse: pair.reverse,
+	}
+}
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_qa_parse(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		source string
+		want   Result
+	}{
+		{
+			name: "empty file",
+			path: "/QA.md",
+			want: Result{Deck: "QA", Cards: []Card{}},
+		},
+		{
+			name:   "prefixes",
+			path:   "/QA.md",
+			source: "Q: What is the capital of France?\nA: Paris.\n\nQ: First question\non two lines\nA: First answer\nQ: Second question\nA: Second answer\non two lines\n",
+			want: Result{Deck: "QA", Cards: []Card{
+				{
+					Content:  "What is the capital of France?\n\n---\n\nParis.\n",
+					Fields:   nameFields("What is the capital of France?"),
+					Path:     "/QA.md",
+					Position: "QAmd0000",
+				},
+				{
+					Content:  "First question\non two lines\n\n---\n\nFirst answer\n",
+					Fields:   nameFields("First question"),
+					Path:     "/QA.md",
+					Position: "QAmd0001",
+				},
+				{
+					Content:  "Second question\n\n---\n\nSecond answer\non two lines\n",
+					Fields:   nameFields("Second question"),
+					Path:     "/QA.md",
+					Position: "QAmd0002",
+				},
+			}},
+		},
+		{
+			name:   "inline separators",
+			path:   "/QA.md",
+			source: "# Title\n\nSome text.\nBonjour :: Hello\nChat ::: Cat\n\n```\nstd::cout\n?\n```\n",
+			want: Result{Deck: "QA", Cards: []Card{
+				{
+					Content:  "Bonjour\n\n---\n\nHello\n",
+					Fields:   nameFields("Bonjour"),
+					Path:     "/QA.md",
+					Position: "QAmd0000",
+				},
+				{
+					Content:       "Chat\n\n---\n\nCat\n",
+					Fields:        nameFields("Chat"),
+					Path:          "/QA.md",
+					Position:      "QAmd0001",
+					ReviewReverse: true,
+				},
+			}},
+		},
+		{
+			name:   "inline separators in code spans",
+			path:   "/QA.md",
+			source: "Use `std::vector` here\n``a::b`` :: `c::d`\n`unmatched :: backtick\n",
+			want: Result{Deck: "QA", Cards: []Card{
+				{
+					Content:  "``a::b``\n\n---\n\n`c::d`\n",
+					Fields:   nameFields("``a::b``"),
+					Path:     "/QA.md",
+					Position: "QAmd0000",
+				},
+				{
+					Content:  "`unmatched\n\n---\n\nbacktick\n",
+					Fields:   nameFields("`unmatched"),
+					Path:     "/QA.md",
+					Position: "QAmd0001",
+				},
+			}},
+		},
+		{
+			name:   "multiline separators",
+			path:   "/QA.md",
+			source: "Question\n?\nAnswer\n```go\n\nfmt.Println(\"::\")\n```\n\nForward\nquestion\n??\nReversed\n\nNo card\n?\n",
+			want: Result{Deck: "QA", Cards: []Card{
+				{
+					Content:  "Question\n\n---\n\nAnswer\n```go\n\nfmt.Println(\"::\")\n```\n",
+					Fields:   nameFields("Question"),
+					Path:     "/QA.md",
+					Position: "QAmd0000",
+				},
+				{
+					Content:       "Forward\nquestion\n\n---\n\nReversed\n",
+					Fields:        nameFields("Forward"),
+					Path:          "/QA.md",
+					Position:      "QAmd0001",
+					ReviewReverse: true,
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newQA().parse(tt.path, []byte(tt.source))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/yuin/goldmark/ast"
@@ -10,16 +11,48 @@ import (
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
 	"github.com/yuin/goldmark/util"
+
+	"github.com/leonhfr/mochi/internal/config"
 )
 
+// tableDecoder is the interface implemented by the sources of tables.
+type tableDecoder interface {
+	decode(source []byte) ([]string, [][]string, error)
+}
+
 // table represents a table parser.
 //
-// Each row returns a separate card.
+// Each row returns a separate card. The card ID of a row of a markdown
+// table is written at the end of its last cell.
 type table struct {
+	decoder tableDecoder
+}
+
+func newTable(decoder tableDecoder) *table {
+	return &table{
+		decoder: decoder,
+	}
+}
+
+func (t *table) parse(path string, source []byte) (Result, error) {
+	headers, rows, err := t.decoder.decode(source)
+	return Result{
+		Deck:  getNameFromPath(path),
+		Cards: getTableCards(path, headers, rows),
+	}, err
+}
+
+// blocks implements the identifier interface.
+func (t *table) blocks(source []byte) []block {
+	return decoderBlocks(t.decoder, source)
+}
+
+// markdownTable represents a decoder of markdown tables.
+type markdownTable struct {
 	parser parser.Parser
 }
 
-func newTable() *table {
+func newMarkdownTable() *markdownTable {
 	p := parser.NewParser(
 		parser.WithBlockParsers(
 			parser.DefaultBlockParsers()...,
@@ -33,12 +66,13 @@ func newTable() *table {
 			util.Prioritized(extension.NewTableASTTransformer(), 200),
 		),
 	)
-	return &table{
+	return &markdownTable{
 		parser: p,
 	}
 }
 
-func (t *table) parse(path string, source []byte) (Result, error) {
+// decode implements the tableDecoder interface.
+func (t *markdownTable) decode(source []byte) ([]string, [][]string, error) {
 	var headers []string
 	var rows [][]string
 	var parsingRows, resetRow bool
@@ -66,10 +100,57 @@ func (t *table) parse(path string, source []byte) (Result, error) {
 		return ast.WalkContinue, nil
 	})
 
-	return Result{
-		Deck:  getNameFromPath(path),
-		Cards: getTableCards(path, headers, rows),
-	}, err
+	return headers, rows, err
+}
+
+// blocks implements the identifier interface.
+//
+// Each row is a block, whose card ID is inserted at the end of its last cell.
+func (t *markdownTable) blocks(source []byte) []block {
+	var blocks []block
+	doc := t.parser.Parse(text.NewReader(source))
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		row, ok := n.(*east.TableRow)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		first, last := row.FirstChild(), row.LastChild()
+		if first == nil || first.Lines().Len() == 0 || last.Lines().Len() == 0 {
+			return ast.WalkSkipChildren, nil
+		}
+
+		start, stop := first.Lines().At(0).Start, last.Lines().At(0).Stop
+		blocks = append(blocks, block{start: start, stop: stop, insert: stop, prefix: " "})
+		return ast.WalkSkipChildren, nil
+	})
+	return blocks
+}
+
+// decoderBlocks returns the blocks of the decoders that support card IDs.
+func decoderBlocks(decoder tableDecoder, source []byte) []block {
+	if id, ok := decoder.(identifier); ok {
+		return id.blocks(source)
+	}
+	return nil
+}
+
+// rowCardID returns the card ID found in the last cell of the row,
+// and the cells without it.
+func rowCardID(cells []string) (string, []string) {
+	if len(cells) == 0 {
+		return "", cells
+	}
+
+	last := len(cells) - 1
+	id, cell := extractCardID([]byte(cells[last]))
+	if id == "" {
+		return "", cells
+	}
+
+	cells = slices.Clone(cells)
+	cells[last] = strings.TrimSpace(string(cell))
+	return id, cells
 }
 
 func getTableCards(path string, headers []string, rows [][]string) []Card {
@@ -86,7 +167,9 @@ func getTableCards(path string, headers []string, rows [][]string) []Card {
 func newTableCard(headers, cells []string, path string, index int) Card {
 	filename := getFilename(path)
 	position := fmt.Sprintf("%s%04d", filename, index)
+	id, cells := rowCardID(cells)
 	return Card{
+		ID:       id,
 		Content:  tableContent(headers, cells),
 		Fields:   nameFields(strings.Join(cells, "|")),
 		Path:     path,
@@ -101,3 +184,77 @@ func tableContent(headers, cells []string) string {
 	}
 	return fmt.Sprintf("%s\n", strings.Join(rows, "\n"))
 }
+
+// tableTemplate represents a table parser filling a template.
+//
+// Each row returns a separate card whose fields are filled from the columns.
+// The name column is used as the card name and identity.
+type tableTemplate struct {
+	decoder tableDecoder
+	config  config.TableTemplate
+}
+
+func newTableTemplate(decoder tableDecoder, config config.TableTemplate) *tableTemplate {
+	return &tableTemplate{
+		decoder: decoder,
+		config:  config,
+	}
+}
+
+// blocks implements the identifier interface.
+func (t *tableTemplate) blocks(source []byte) []block {
+	return decoderBlocks(t.decoder, source)
+}
+
+func (t *tableTemplate) parse(path string, source []byte) (Result, error) {
+	headers, rows, err := t.decoder.decode(source)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if len(headers) > 0 && !slices.Contains(headers, t.config.Name) {
+		return Result{}, fmt.Errorf("table template: name column %s not found in %s", t.config.Name, path)
+	}
+
+	cards := []Card{}
+	for _, row := range rows {
+		if len(headers) != len(row) {
+			continue
+		}
+		if card, ok := newTableTemplateCard(headers, row, path, t.config); ok {
+			cards = append(cards, card)
+		}
+	}
+
+	return Result{
+		Deck:  getNameFromPath(path),
+		Cards: cards,
+	}, nil
+}
+
+func newTableTemplateCard(headers, cells []string, path string, config config.TableTemplate) (Card, bool) {
+	id, cells := rowCardID(cells)
+	fields := map[string]string{}
+	for i, header := range headers {
+		value := strings.TrimSpace(cells[i])
+		if header == config.Name {
+			fields["name"] = value
+		}
+		if fieldID, ok := config.Fields[header]; ok && value != "" {
+			fields[fieldID] = value
+		}
+	}
+
+	name := fields["name"]
+	if name == "" {
+		return Card{}, false
+	}
+
+	return Card{
+		ID:         id,
+		Fields:     fields,
+		TemplateID: config.TemplateID,
+		Path:       path,
+		Position:   sanitizePosition(name),
+	}, true
+}
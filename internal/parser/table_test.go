@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/internal/config"
 )
 
 var tableSource = `
@@ -45,7 +47,99 @@ func Test_table_parse(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := newTable().parse(tt.path, []byte(tt.source))
+			got, err := newTable(newMarkdownTable()).parse(tt.path, []byte(tt.source))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_tableTemplate_parse(t *testing.T) {
+	template := config.TableTemplate{
+		TemplateID: "TEMPLATE_ID",
+		Name:       "Infinitive",
+		Fields: map[string]string{
+			"Infinitive": "INFINITIVE_ID",
+			"Past":       "PAST_ID",
+			"English":    "ENGLISH_ID",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template config.TableTemplate
+		path     string
+		source   string
+		want     Result
+		err      bool
+	}{
+		{
+			name:     "should fill the template fields",
+			template: template,
+			path:     "/testdata/verbs/Strong Verbs.md",
+			source:   tableSource + "|  | missing | name | row | - |\n",
+			want: Result{
+				Deck: "Strong Verbs", Cards: []Card{
+					{
+						Fields:     map[string]string{"name": "backen", "INFINITIVE_ID": "backen", "PAST_ID": "[buk]", "ENGLISH_ID": "to bake"},
+						TemplateID: "TEMPLATE_ID",
+						Path:       "/testdata/verbs/Strong Verbs.md",
+						Position:   "backen",
+					},
+					{
+						Fields:     map[string]string{"name": "befehlen", "INFINITIVE_ID": "befehlen", "PAST_ID": "befahl", "ENGLISH_ID": "to order, instruct"},
+						TemplateID: "TEMPLATE_ID",
+						Path:       "/testdata/verbs/Strong Verbs.md",
+						Position:   "befehlen",
+					},
+				},
+			},
+		},
+		{
+			name:     "non-ASCII names",
+			template: template,
+			path:     "/testdata/verbs/Strong Verbs.md",
+			source:   "| Infinitive | Past | English |\n|---|---|---|\n| schließen | schloss | to close |\n| übertreffen | übertraf | to surpass |\n",
+			want: Result{
+				Deck: "Strong Verbs", Cards: []Card{
+					{
+						Fields:     map[string]string{"name": "schließen", "INFINITIVE_ID": "schließen", "PAST_ID": "schloss", "ENGLISH_ID": "to close"},
+						TemplateID: "TEMPLATE_ID",
+						Path:       "/testdata/verbs/Strong Verbs.md",
+						Position:   "schließen",
+					},
+					{
+						Fields:     map[string]string{"name": "übertreffen", "INFINITIVE_ID": "übertreffen", "PAST_ID": "übertraf", "ENGLISH_ID": "to surpass"},
+						TemplateID: "TEMPLATE_ID",
+						Path:       "/testdata/verbs/Strong Verbs.md",
+						Position:   "übertreffen",
+					},
+				},
+			},
+		},
+		{
+			name:     "no table",
+			template: template,
+			path:     "/testdata/verbs/Empty.md",
+			source:   "Some text.\n",
+			want:     Result{Deck: "Empty", Cards: []Card{}},
+		},
+		{
+			name:     "missing name column",
+			template: config.TableTemplate{TemplateID: "TEMPLATE_ID", Name: "Verb"},
+			path:     "/testdata/verbs/Strong Verbs.md",
+			source:   tableSource,
+			err:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTableTemplate(newMarkdownTable(), tt.template).parse(tt.path, []byte(tt.source))
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
 			assert.NoError(t, err)
 			assert.Equal(t, tt.want, got)
 		})
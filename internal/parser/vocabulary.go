@@ -94,12 +94,16 @@ func parseParagraph(paragraph *ast.Paragraph, source []byte) (string, []string,
 }
 
 func newVocabularyCard(word string, examples, notes []string, path string, config config.VocabularyTemplate) Card {
-	return Card{
+	card := Card{
 		Fields:     vocabularyFields(word, examples, notes, config),
 		TemplateID: config.TemplateID,
 		Path:       path,
 		Position:   sanitizePosition(word),
 	}
+	if config.PronunciationID != "" {
+		card.Audio = map[string]string{config.PronunciationID: word}
+	}
+	return card
 }
 
 func vocabularyFields(word string, examples, notes []string, config config.VocabularyTemplate) map[string]string {
@@ -113,6 +113,26 @@ func Test_vocabulary_parse(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: "should parse vocabulary with pronunciation",
+			path: "/testdata/languages/de/vocabulary/s.md",
+			config: config.VocabularyTemplate{
+				TemplateID:      "GERMAN_TEMPLATE",
+				PronunciationID: "PRONUNCIATION_ID",
+			},
+			source: "Spaziergang\n",
+			want: Result{Cards: []Card{
+				{
+					Fields: map[string]string{
+						"name": "Spaziergang",
+					},
+					TemplateID: "GERMAN_TEMPLATE",
+					Path:       "/testdata/languages/de/vocabulary/s.md",
+					Position:   "Spaziergang",
+					Audio:      map[string]string{"PRONUNCIATION_ID": "Spaziergang"},
+				},
+			}},
+		},
 	}
 
 	for _, tt := range tests {
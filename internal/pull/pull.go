@@ -0,0 +1,324 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/leonhfr/mochi/internal/config"
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/mochi"
+)
+
+const (
+	configName   = "mochi.yml"
+	mediaDir     = "media"
+	untitledName = "Untitled"
+)
+
+var mediaRegexp = regexp.MustCompile(`@media/([^\s)"'\]]+)`)
+
+// Logger is the interface to log output.
+type Logger interface {
+	Infof(format string, args ...any)
+}
+
+// Client is the interface the mochi client should implement to pull the decks.
+type Client interface {
+	ListDecks(ctx context.Context) ([]mochi.Deck, error)
+	ListCardsInDeck(ctx context.Context, deckID string) ([]mochi.Card, error)
+	GetAttachment(ctx context.Context, cardID, filename string) ([]byte, error)
+}
+
+// Writer is the interface to write the workspace files.
+type Writer interface {
+	Write(path string) (io.WriteCloser, error)
+}
+
+// Lockfile is the interface the lockfile should implement to record the pulled decks and cards.
+type Lockfile interface {
+	Lock()
+	Unlock()
+	SetDeck(id, parentID, path, name string)
+	SetCard(deckID, cardID string, card lock.Card) error
+}
+
+// Result contains the result of a pull.
+type Result struct {
+	Decks       int
+	Cards       int
+	Attachments int
+	Skipped     int // archived cards and cards using a template
+}
+
+// Pull exports the mochi decks into the workspace.
+//
+// Each deck becomes a directory and each card a markdown file embedding
+// its card ID, so that the workspace can be synced back without duplicates.
+// The config and the lockfile entries are generated accordingly.
+func Pull(ctx context.Context, logger Logger, client Client, w Writer, lf Lockfile, workspace string) (Result, error) {
+	decks, err := client.ListDecks(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	paths := deckPaths(decks)
+	slices.SortFunc(decks, func(a, b mochi.Deck) int {
+		return strings.Compare(paths[a.ID], paths[b.ID])
+	})
+
+	lf.Lock()
+	for _, deck := range decks {
+		lf.SetDeck(deck.ID, parentID(paths, deck), paths[deck.ID], deck.Name)
+	}
+	lf.Unlock()
+
+	result := Result{Decks: len(decks)}
+	media := make(map[string]bool)
+	for _, deck := range decks {
+		logger.Infof("pull(%s): fetching cards", paths[deck.ID])
+		cards, err := client.ListCardsInDeck(ctx, deck.ID)
+		if err != nil {
+			return result, err
+		}
+
+		filenames := make(map[string]bool)
+		for _, card := range cards {
+			if card.Archived || card.TemplateID != "" {
+				logger.Infof("pull(%s): skipping card %s", paths[deck.ID], card.ID)
+				result.Skipped++
+				continue
+			}
+
+			attachments, err := pullAttachments(ctx, client, w, workspace, media, card)
+			if err != nil {
+				return result, err
+			}
+
+			name, body := cardFile(card)
+			filename := uniqueName(filenames, sanitizeName(name), " ", ".md")
+			dir := paths[deck.ID]
+			body = rewriteMedia(body, mediaPath(dir), attachments)
+			if err := writeFile(w, filepath.Join(workspace, dir, filename), cardSource(card.ID, body)); err != nil {
+				return result, err
+			}
+
+			lf.Lock()
+			err = lf.SetCard(deck.ID, card.ID, lock.Card{Filename: filename, ID: card.ID, Name: card.Name})
+			lf.Unlock()
+			if err != nil {
+				return result, err
+			}
+
+			result.Cards++
+			result.Attachments += len(attachments)
+		}
+	}
+
+	source, err := configSource(decks, paths, result.Skipped > 0)
+	if err != nil {
+		return result, err
+	}
+
+	return result, writeFile(w, filepath.Join(workspace, configName), source)
+}
+
+// deckPaths returns the directory of each deck, indexed by deck ID.
+//
+// Sibling decks whose names collide are given a numbered suffix.
+func deckPaths(decks []mochi.Deck) map[string]string {
+	ids := make(map[string]bool, len(decks))
+	for _, deck := range decks {
+		ids[deck.ID] = true
+	}
+
+	sorted := slices.Clone(decks)
+	slices.SortFunc(sorted, func(a, b mochi.Deck) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	children := make(map[string][]mochi.Deck)
+	for _, deck := range sorted {
+		parent := deck.ParentID
+		if !ids[parent] {
+			parent = ""
+		}
+		children[parent] = append(children[parent], deck)
+	}
+
+	paths := make(map[string]string, len(decks))
+	var walk func(parentID, parentPath string)
+	walk = func(parentID, parentPath string) {
+		used := make(map[string]bool)
+		if parentID == "" {
+			used[mediaDir] = true
+		}
+		for _, deck := range children[parentID] {
+			paths[deck.ID] = filepath.Join(parentPath, uniqueName(used, sanitizeName(deck.Name), " ", ""))
+			walk(deck.ID, paths[deck.ID])
+		}
+	}
+	walk("", "/")
+
+	return paths
+}
+
+func parentID(paths map[string]string, deck mochi.Deck) string {
+	if _, ok := paths[deck.ParentID]; ok {
+		return deck.ParentID
+	}
+	return ""
+}
+
+// cardFile returns the name of a card and the markdown body of its file.
+//
+// The note parser turns the filename into the card title, so a leading
+// title heading is removed from the body.
+func cardFile(card mochi.Card) (string, string) {
+	content := strings.TrimLeft(card.Content, "\n")
+	title, body, _ := strings.Cut(content, "\n")
+	if name, ok := strings.CutPrefix(title, "# "); ok && strings.TrimSpace(name) != "" {
+		return strings.TrimSpace(name), strings.TrimLeft(body, "\n")
+	}
+
+	switch {
+	case card.Name != "":
+		return card.Name, content
+	case card.Fields["name"].Value != "":
+		return card.Fields["name"].Value, content
+	default:
+		return untitledName, content
+	}
+}
+
+func cardSource(cardID, body string) []byte {
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return []byte(fmt.Sprintf("<!-- mochi-id: %s -->\n%s", cardID, body))
+}
+
+// pullAttachments downloads the attachments of a card into the media directory.
+//
+// Returns the names of the written files indexed by attachment filename.
+func pullAttachments(ctx context.Context, client Client, w Writer, workspace string, media map[string]bool, card mochi.Card) (map[string]string, error) {
+	filenames := make([]string, 0, len(card.Attachments))
+	for filename := range card.Attachments {
+		filenames = append(filenames, filename)
+	}
+	slices.Sort(filenames)
+
+	attachments := make(map[string]string, len(filenames))
+	for _, filename := range filenames {
+		data, err := client.GetAttachment(ctx, card.ID, filename)
+		if err != nil {
+			return nil, err
+		}
+
+		ext := filepath.Ext(filename)
+		// links cannot contain spaces
+		base := strings.ReplaceAll(sanitizeName(strings.TrimSuffix(filename, ext)), " ", "-")
+		name := uniqueName(media, base, "-", ext)
+		if err := writeFile(w, filepath.Join(workspace, mediaDir, name), data); err != nil {
+			return nil, err
+		}
+		attachments[filename] = name
+	}
+
+	return attachments, nil
+}
+
+// mediaPath returns the relative path from a deck directory to the media directory.
+func mediaPath(dir string) string {
+	depth := strings.Count(strings.Trim(dir, "/"), "/") + 1
+	return strings.Repeat("../", depth) + mediaDir
+}
+
+// rewriteMedia replaces the mochi attachment links with relative links.
+func rewriteMedia(body, media string, attachments map[string]string) string {
+	return mediaRegexp.ReplaceAllStringFunc(body, func(link string) string {
+		if name, ok := attachments[strings.TrimPrefix(link, "@media/")]; ok {
+			return fmt.Sprintf("%s/%s", media, name)
+		}
+		return link
+	})
+}
+
+// uniqueName returns name with the extension, numbered if already used.
+func uniqueName(used map[string]bool, name, sep, ext string) string {
+	candidate := name + ext
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s%s%d%s", name, sep, i, ext)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// sanitizeName removes the characters that are not allowed in filenames.
+func sanitizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|`, r):
+			return '-'
+		case r < ' ':
+			return -1
+		default:
+			return r
+		}
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+	if name == "" {
+		return untitledName
+	}
+	return name
+}
+
+type configFile struct {
+	SkipRoot bool            `yaml:"skipRoot"`
+	CardIDs  bool            `yaml:"cardIDs"`
+	Deletion config.Deletion `yaml:"deletion,omitempty"`
+	Decks    []configDeck    `yaml:"decks"`
+}
+
+type configDeck struct {
+	Path string `yaml:"path"`
+	Name string `yaml:"name"`
+}
+
+// configSource returns the config matching the pulled decks.
+//
+// When cards have been skipped, the deletion policy keeps them untouched.
+func configSource(decks []mochi.Deck, paths map[string]string, skipped bool) ([]byte, error) {
+	cfg := configFile{
+		SkipRoot: true,
+		CardIDs:  true,
+		Decks:    make([]configDeck, 0, len(decks)),
+	}
+	if skipped {
+		cfg.Deletion = config.DeletionKeep
+	}
+	for _, deck := range decks {
+		cfg.Decks = append(cfg.Decks, configDeck{Path: paths[deck.ID], Name: deck.Name})
+	}
+	return yaml.Marshal(cfg)
+}
+
+func writeFile(w Writer, path string, data []byte) error {
+	wc, err := w.Write(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := wc.Write(data); err != nil {
+		wc.Close()
+		return err
+	}
+
+	return wc.Close()
+}
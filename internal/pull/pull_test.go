@@ -0,0 +1,109 @@
+package pull
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/mochi"
+)
+
+func Test_deckPaths(t *testing.T) {
+	decks := []mochi.Deck{
+		{ID: "DECK_1", Name: "Languages"},
+		{ID: "DECK_2", Name: "German", ParentID: "DECK_1"},
+		{ID: "DECK_3", Name: "german", ParentID: "DECK_1"},
+		{ID: "DECK_4", Name: "Media"},
+		{ID: "DECK_5", Name: "Orphan", ParentID: "UNKNOWN"},
+		{ID: "DECK_6", Name: "What? A/B"},
+	}
+	want := map[string]string{
+		"DECK_1": "/Languages",
+		"DECK_2": "/Languages/German",
+		"DECK_3": "/Languages/german 2",
+		"DECK_4": "/Media 2",
+		"DECK_5": "/Orphan",
+		"DECK_6": "/What- A-B",
+	}
+
+	got := deckPaths(decks)
+	assert.Equal(t, want, got)
+}
+
+func Test_cardFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		card     mochi.Card
+		wantName string
+		wantBody string
+	}{
+		{
+			name:     "title heading",
+			card:     mochi.Card{Name: "Other", Content: "# Title\n\nContent.\n"},
+			wantName: "Title",
+			wantBody: "Content.\n",
+		},
+		{
+			name:     "card name",
+			card:     mochi.Card{Name: "Name", Content: "## Subtitle\n\nContent.\n"},
+			wantName: "Name",
+			wantBody: "## Subtitle\n\nContent.\n",
+		},
+		{
+			name:     "name field",
+			card:     mochi.Card{Content: "Content.", Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Field"}}},
+			wantName: "Field",
+			wantBody: "Content.",
+		},
+		{
+			name:     "untitled",
+			card:     mochi.Card{Content: "Content."},
+			wantName: "Untitled",
+			wantBody: "Content.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotName, gotBody := cardFile(tt.card)
+			assert.Equal(t, tt.wantName, gotName)
+			assert.Equal(t, tt.wantBody, gotBody)
+		})
+	}
+}
+
+func Test_cardSource(t *testing.T) {
+	assert.Equal(t, "<!-- mochi-id: CARD_ID -->\nContent.\n", string(cardSource("CARD_ID", "Content.")))
+	assert.Equal(t, "<!-- mochi-id: CARD_ID -->\n", string(cardSource("CARD_ID", "")))
+}
+
+func Test_rewriteMedia(t *testing.T) {
+	body := "![Image](@media/abc.png) ![Unknown](@media/def.png)"
+	attachments := map[string]string{"abc.png": "abc-2.png"}
+	want := "![Image](../../media/abc-2.png) ![Unknown](@media/def.png)"
+
+	got := rewriteMedia(body, mediaPath("/Languages/German"), attachments)
+	assert.Equal(t, want, got)
+}
+
+func Test_uniqueName(t *testing.T) {
+	used := make(map[string]bool)
+	assert.Equal(t, "Note.md", uniqueName(used, "Note", " ", ".md"))
+	assert.Equal(t, "note 2.md", uniqueName(used, "note", " ", ".md"))
+	assert.Equal(t, "Note 3.md", uniqueName(used, "Note", " ", ".md"))
+	assert.Equal(t, "image.png", uniqueName(used, "image", "-", ".png"))
+	assert.Equal(t, "image-2.png", uniqueName(used, "image", "-", ".png"))
+}
+
+func Test_configSource(t *testing.T) {
+	decks := []mochi.Deck{{ID: "DECK_1", Name: "Languages"}}
+	paths := map[string]string{"DECK_1": "/Languages"}
+
+	got, err := configSource(decks, paths, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "skipRoot: true\ncardIDs: true\ndecks:\n    - path: /Languages\n      name: Languages\n", string(got))
+
+	got, err = configSource(decks, paths, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "skipRoot: true\ncardIDs: true\ndeletion: keep\ndecks:\n    - path: /Languages\n      name: Languages\n", string(got))
+}
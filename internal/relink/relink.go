@@ -0,0 +1,282 @@
+package relink
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/leonhfr/mochi/internal/card"
+	"github.com/leonhfr/mochi/internal/deck"
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/mochi"
+)
+
+// Logger is the interface to log output.
+type Logger interface {
+	Infof(format string, args ...any)
+}
+
+// Client is the interface the mochi client should implement to relink the cards.
+type Client interface {
+	ListCardsInDeck(ctx context.Context, deckID string) ([]mochi.Card, error)
+}
+
+// Lockfile is the interface the lockfile should implement to record the matches.
+type Lockfile interface {
+	Lock()
+	Unlock()
+	SetDeck(id, parentID, path, name string)
+	SetVirtualDeck(id, parentID, name string)
+	SetCard(deckID, cardID string, card lock.Card) error
+}
+
+// Directory contains the cards parsed from a workspace directory.
+type Directory struct {
+	Path  string
+	Cards []card.Card
+}
+
+// Result contains the result of a relink.
+type Result struct {
+	Decks       int
+	Cards       int
+	Ambiguities []string // matches that have not been recorded
+}
+
+// Relink matches the directories and their cards with the remote decks
+// and cards, and records the matches in the lockfile.
+//
+// Decks are matched by name and parent hierarchy, cards by name then position.
+// Ambiguous matches are reported and left out of the lockfile.
+func Relink(ctx context.Context, logger Logger, client Client, config deck.CreateConfig, lf Lockfile, decks []mochi.Deck, dirs []Directory) (Result, error) {
+	r := &relinker{
+		config:  config,
+		lf:      lf,
+		decks:   decks,
+		matched: make(map[string]string),
+		failed:  make(map[string]bool),
+	}
+
+	for _, dir := range dirs {
+		deckID, ok := r.matchDirectory(dir.Path)
+		if !ok {
+			logger.Infof("relink(%s): no matching deck", dir.Path)
+			continue
+		}
+
+		groups := groupCards(dir.Cards)
+		for _, name := range sortedKeys(groups) {
+			groupID, ok := deckID, true
+			if name != "" {
+				groupID, ok = r.matchVirtual(dir.Path, deckID, name)
+			}
+			if !ok {
+				logger.Infof("relink(%s): no matching deck for %s", dir.Path, name)
+				continue
+			}
+
+			logger.Infof("relink(%s): fetching cards", dir.Path)
+			mochiCards, err := client.ListCardsInDeck(ctx, groupID)
+			if err != nil {
+				return r.result, err
+			}
+
+			if err := r.recordCards(groupID, mochiCards, groups[name]); err != nil {
+				return r.result, err
+			}
+		}
+	}
+
+	return r.result, nil
+}
+
+type relinker struct {
+	config  deck.CreateConfig
+	lf      Lockfile
+	decks   []mochi.Deck
+	matched map[string]string // deck ID indexed by path
+	failed  map[string]bool   // indexed by path
+	result  Result
+}
+
+// matchDirectory matches the deck of a directory and its ancestors.
+func (r *relinker) matchDirectory(path string) (string, bool) {
+	parentID := ""
+	for _, current := range ancestors(path) {
+		if deckID, ok := r.matched[current]; ok {
+			parentID = deckID
+			continue
+		}
+
+		if r.failed[current] {
+			return "", false
+		}
+
+		name := deck.Name(r.config, current)
+		candidates := r.candidates(parentID, name)
+		if len(candidates) != 1 {
+			r.failed[current] = true
+			if len(candidates) > 1 {
+				r.ambiguous("deck %s: %d remote decks named %q", current, len(candidates), name)
+			}
+			return "", false
+		}
+
+		deckID := candidates[0].ID
+		r.lf.Lock()
+		r.lf.SetDeck(deckID, parentID, current, name)
+		r.lf.Unlock()
+		r.matched[current] = deckID
+		r.result.Decks++
+		parentID = deckID
+	}
+
+	return parentID, true
+}
+
+// matchVirtual matches a virtual deck inside a directory deck.
+func (r *relinker) matchVirtual(path, parentID, name string) (string, bool) {
+	candidates := r.candidates(parentID, name)
+	if len(candidates) != 1 {
+		if len(candidates) > 1 {
+			r.ambiguous("deck %s > %s: %d remote decks named %q", path, name, len(candidates), name)
+		}
+		return "", false
+	}
+
+	deckID := candidates[0].ID
+	r.lf.Lock()
+	r.lf.SetVirtualDeck(deckID, parentID, name)
+	r.lf.Unlock()
+	r.result.Decks++
+	return deckID, true
+}
+
+func (r *relinker) candidates(parentID, name string) []mochi.Deck {
+	var candidates []mochi.Deck
+	for _, d := range r.decks {
+		if d.ParentID == parentID && d.Name == name {
+			candidates = append(candidates, d)
+		}
+	}
+	return candidates
+}
+
+func (r *relinker) recordCards(deckID string, mochiCards []mochi.Card, parsedCards []card.Card) error {
+	matches, ambiguities := matchCards(mochiCards, parsedCards)
+	for _, ambiguity := range ambiguities {
+		r.ambiguous("%s", ambiguity)
+	}
+
+	r.lf.Lock()
+	defer r.lf.Unlock()
+
+	for _, cardID := range sortedKeys(matches) {
+		parsedCard := matches[cardID]
+		if err := r.lf.SetCard(deckID, cardID, lock.Card{Filename: parsedCard.Filename(), ID: parsedCard.ID, Name: parsedCard.Fields["name"]}); err != nil {
+			return err
+		}
+		r.result.Cards++
+	}
+
+	return nil
+}
+
+func (r *relinker) ambiguous(format string, args ...any) {
+	r.result.Ambiguities = append(r.result.Ambiguities, fmt.Sprintf(format, args...))
+}
+
+// matchCards matches the parsed cards with the remote cards by name,
+// then by position when several remote cards share the name.
+//
+// Returns the matched cards indexed by card ID and the ambiguities.
+func matchCards(mochiCards []mochi.Card, parsedCards []card.Card) (map[string]card.Card, []string) {
+	claims := make(map[string][]card.Card)
+	var ambiguities []string
+	for _, parsedCard := range parsedCards {
+		candidates := cardCandidates(mochiCards, parsedCard)
+		switch len(candidates) {
+		case 0:
+		case 1:
+			claims[candidates[0].ID] = append(claims[candidates[0].ID], parsedCard)
+		default:
+			ambiguities = append(ambiguities, fmt.Sprintf("card %s (%s): %d remote cards match", parsedCard.Path, parsedCard.Fields["name"], len(candidates)))
+		}
+	}
+
+	matches := make(map[string]card.Card)
+	for _, cardID := range sortedKeys(claims) {
+		cards := claims[cardID]
+		if len(cards) > 1 {
+			ambiguities = append(ambiguities, fmt.Sprintf("card ID %s: matches %d parsed cards", cardID, len(cards)))
+			continue
+		}
+		matches[cardID] = cards[0]
+	}
+
+	return matches, ambiguities
+}
+
+func cardCandidates(mochiCards []mochi.Card, parsedCard card.Card) []mochi.Card {
+	name := parsedCard.Fields["name"]
+	var byName []mochi.Card
+	for _, mochiCard := range mochiCards {
+		if name != "" && mochiCard.Fields["name"].Value == name {
+			byName = append(byName, mochiCard)
+		}
+	}
+
+	if len(byName) == 1 || parsedCard.Position == "" {
+		return byName
+	}
+
+	pool := byName
+	if name == "" {
+		pool = mochiCards
+	}
+
+	var byPosition []mochi.Card
+	for _, mochiCard := range pool {
+		if mochiCard.Pos == parsedCard.Position {
+			byPosition = append(byPosition, mochiCard)
+		}
+	}
+
+	if len(byPosition) == 0 {
+		return byName
+	}
+	return byPosition
+}
+
+// ancestors returns the directory paths from the top-level directory to path.
+func ancestors(path string) []string {
+	if path == "/" {
+		return []string{path}
+	}
+
+	var paths []string
+	for ; path != "/"; path = filepath.Dir(path) {
+		paths = append(paths, path)
+	}
+	slices.Reverse(paths)
+	return paths
+}
+
+func groupCards(cards []card.Card) map[string][]card.Card {
+	groups := make(map[string][]card.Card)
+	for _, c := range cards {
+		groups[c.Base()] = append(groups[c.Base()], c)
+	}
+	return groups
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, strings.Compare)
+	return keys
+}
@@ -0,0 +1,115 @@
+package relink
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/internal/card"
+	"github.com/leonhfr/mochi/internal/config"
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/internal/parser"
+	"github.com/leonhfr/mochi/internal/test"
+	"github.com/leonhfr/mochi/mochi"
+)
+
+func Test_Relink(t *testing.T) {
+	cfg := test.NewMockConfig(test.Config{Deck: []test.ConfigDeck{
+		{Path: "/languages", Deck: config.Deck{Name: "Languages"}, OK: true},
+		{Path: "/languages/german", Deck: config.Deck{}, OK: false},
+		{Path: "/duplicated", Deck: config.Deck{}, OK: false},
+	}})
+	decks := []mochi.Deck{
+		{ID: "DECK_1", Name: "Languages"},
+		{ID: "DECK_2", Name: "German", ParentID: "DECK_1"},
+		{ID: "DECK_3", Name: "German"},
+		{ID: "DECK_4", Name: "Duplicated"},
+		{ID: "DECK_5", Name: "Duplicated"},
+	}
+	note := card.Card{Card: parser.Card{
+		ID:       "IDENTITY",
+		Fields:   map[string]string{"name": "Note"},
+		Path:     "/languages/german/note.md",
+		Position: "Note",
+	}}
+	dirs := []Directory{
+		{Path: "/languages/german", Cards: []card.Card{note}},
+		{Path: "/duplicated", Cards: []card.Card{note}},
+	}
+	client := test.NewMockMochi(test.Mochi{ListCardsInDeck: []test.MochiListCardsInDeck{
+		{DeckID: "DECK_2", Cards: []mochi.Card{
+			{ID: "CARD_1", Pos: "Note", Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Note"}}},
+			{ID: "CARD_2", Pos: "Other", Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Other"}}},
+		}},
+	}})
+	lf := test.NewMockLockfile(test.Lockfile{
+		Lock: 3,
+		SetDeck: []test.LockfileSetDeck{
+			{ID: "DECK_1", ParentID: "", Path: "/languages", Name: "Languages"},
+			{ID: "DECK_2", ParentID: "DECK_1", Path: "/languages/german", Name: "German"},
+		},
+		SetCard: []test.LockfileSetCard{
+			{DeckID: "DECK_2", CardID: "CARD_1", Card: lock.Card{Filename: "note.md", ID: "IDENTITY", Name: "Note"}},
+		},
+	})
+
+	want := Result{
+		Decks:       2,
+		Cards:       1,
+		Ambiguities: []string{`deck /duplicated: 2 remote decks named "Duplicated"`},
+	}
+
+	got, err := Relink(context.Background(), testLogger{}, client, cfg, lf, decks, dirs)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+	client.AssertExpectations(t)
+	lf.AssertExpectations(t)
+}
+
+func Test_matchCards(t *testing.T) {
+	mochiCards := []mochi.Card{
+		{ID: "CARD_1", Pos: "A", Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Unique"}}},
+		{ID: "CARD_2", Pos: "B", Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Twin"}}},
+		{ID: "CARD_3", Pos: "C", Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Twin"}}},
+		{ID: "CARD_4", Pos: "D", Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Triplet"}}},
+		{ID: "CARD_5", Pos: "D", Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Triplet"}}},
+		{ID: "CARD_6", Pos: "E", Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Claimed"}}},
+	}
+	unique := newCard("Unique", "Z")
+	twin := newCard("Twin", "C")
+	triplet := newCard("Triplet", "D")
+	claimed := newCard("Claimed", "E")
+	missing := newCard("Missing", "F")
+
+	wantMatches := map[string]card.Card{
+		"CARD_1": unique,
+		"CARD_3": twin,
+	}
+	wantAmbiguities := []string{
+		"card /note.md (Triplet): 2 remote cards match",
+		"card ID CARD_6: matches 2 parsed cards",
+	}
+
+	gotMatches, gotAmbiguities := matchCards(mochiCards, []card.Card{unique, twin, triplet, claimed, claimed, missing})
+	assert.Equal(t, wantMatches, gotMatches)
+	assert.Equal(t, wantAmbiguities, gotAmbiguities)
+}
+
+func Test_ancestors(t *testing.T) {
+	assert.Equal(t, []string{"/"}, ancestors("/"))
+	assert.Equal(t, []string{"/a"}, ancestors("/a"))
+	assert.Equal(t, []string{"/a", "/a/b", "/a/b/c"}, ancestors("/a/b/c"))
+}
+
+func newCard(name, position string) card.Card {
+	return card.Card{Card: parser.Card{
+		Fields:   map[string]string{"name": name},
+		Path:     "/note.md",
+		Position: position,
+	}}
+}
+
+type testLogger struct{}
+
+func (testLogger) Infof(string, ...any) {}
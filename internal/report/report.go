@@ -0,0 +1,78 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/leonhfr/mochi/internal/request"
+)
+
+// Report represents a machine-readable sync report.
+type Report struct {
+	LockfileUpdated bool      `json:"lockfileUpdated"`
+	Requests        []Request `json:"requests"`
+	mu              sync.Mutex
+}
+
+// Request contains the outcome of an executed request.
+type Request struct {
+	Kind        request.Kind `json:"kind"`
+	DeckID      string       `json:"deckID"`
+	CardID      string       `json:"cardID,omitempty"`
+	Filename    string       `json:"filename,omitempty"`
+	Attachments int          `json:"attachments"`
+	DurationMS  int64        `json:"durationMs"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// New returns a new Report.
+func New() *Report {
+	return &Report{Requests: []Request{}}
+}
+
+// Record records the outcome of an executed request.
+//
+// It is safe to call Record concurrently.
+func (r *Report) Record(summary request.Summary, duration time.Duration, err error) {
+	req := NewRequest(summary, duration, err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Requests = append(r.Requests, req)
+}
+
+// NewRequest returns the outcome of an executed request.
+func NewRequest(summary request.Summary, duration time.Duration, err error) Request {
+	req := Request{
+		Kind:        summary.Kind,
+		DeckID:      summary.DeckID,
+		CardID:      summary.CardID,
+		Filename:    summary.Filename,
+		Attachments: summary.Attachments,
+		DurationMS:  duration.Milliseconds(),
+	}
+	if err != nil {
+		req.Error = err.Error()
+	}
+	return req
+}
+
+// JSON returns the JSON encoding of the report.
+func (r *Report) JSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return json.Marshal(r)
+}
+
+// Write writes the JSON encoding of the report to w.
+func (r *Report) Write(w io.Writer) error {
+	bytes, err := r.JSON()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bytes)
+	return err
+}
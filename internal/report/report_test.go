@@ -0,0 +1,37 @@
+package report
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/internal/request"
+)
+
+func Test_Report(t *testing.T) {
+	r := New()
+	r.LockfileUpdated = true
+	r.Record(request.Summary{
+		Kind:        request.KindCreate,
+		DeckID:      "DECK_ID",
+		CardID:      "CARD_ID_1",
+		Filename:    "Lorem ipsum.md",
+		Attachments: 2,
+	}, 1500*time.Millisecond, nil)
+	r.Record(request.Summary{
+		Kind:   request.KindDelete,
+		DeckID: "DECK_ID",
+		CardID: "CARD_ID_2",
+	}, 20*time.Millisecond, errors.New("ERROR"))
+
+	want := `{"lockfileUpdated":true,"requests":[` +
+		`{"kind":"create","deckID":"DECK_ID","cardID":"CARD_ID_1","filename":"Lorem ipsum.md","attachments":2,"durationMs":1500},` +
+		`{"kind":"delete","deckID":"DECK_ID","cardID":"CARD_ID_2","attachments":0,"durationMs":20,"error":"ERROR"}` +
+		`]}`
+
+	got, err := r.JSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, want, string(got))
+}
@@ -4,24 +4,67 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/leonhfr/mochi/internal/lock"
 	"github.com/leonhfr/mochi/mochi"
 )
 
 type archiveCard struct {
-	cardID string
+	deckID      string
+	cardID      string
+	identity    string
+	attachments map[string]mochi.Attachment
+	lockCard    *lock.Card // set when the card is tracked in the lockfile
 }
 
 // ArchiveCard returns a new archive card request.
-func ArchiveCard(cardID string) Request {
+func ArchiveCard(deckID, cardID string) Request {
 	return &archiveCard{
+		deckID: deckID,
 		cardID: cardID,
 	}
 }
 
+// ArchiveTrackedCard returns a new archive card request for a card
+// tracked in the lockfile.
+//
+// Unlike ArchiveCard, the request can be turned into a move by Moves,
+// and the lockfile records that the card was archived so that it is
+// un-archived if its source comes back.
+func ArchiveTrackedCard(deckID string, mochiCard mochi.Card, lockCard lock.Card) Request {
+	return &archiveCard{
+		deckID:      deckID,
+		cardID:      mochiCard.ID,
+		identity:    cardIdentity(lockCard.ID, lockCard.Filename, mochiCard.Fields["name"].Value),
+		attachments: mochiCard.Attachments,
+		lockCard:    &lockCard,
+	}
+}
+
 // Execute implements the Request interface.
-func (r *archiveCard) Execute(ctx context.Context, client Client, _ Lockfile) error {
-	_, err := client.UpdateCard(ctx, r.cardID, mochi.UpdateCardRequest{Archived: true})
-	return err
+func (r *archiveCard) Execute(ctx context.Context, client Client, lf Lockfile) error {
+	if _, err := client.UpdateCard(ctx, r.cardID, mochi.UpdateCardRequest{Archived: true}); err != nil {
+		return err
+	}
+
+	if r.lockCard == nil {
+		return nil
+	}
+
+	lf.Lock()
+	defer lf.Unlock()
+
+	lockCard := *r.lockCard
+	lockCard.Archived = true
+	return lf.SetCard(r.deckID, r.cardID, lockCard)
+}
+
+// Summary implements the Request interface.
+func (r *archiveCard) Summary() Summary {
+	return Summary{
+		Kind:   KindArchive,
+		DeckID: r.deckID,
+		CardID: r.cardID,
+	}
 }
 
 // String implements the fmt.Stringer interface.
@@ -6,12 +6,19 @@ import (
 
 	"github.com/leonhfr/mochi/internal/card"
 	"github.com/leonhfr/mochi/internal/converter"
+	"github.com/leonhfr/mochi/internal/lock"
 	"github.com/leonhfr/mochi/mochi"
 )
 
 type createRequest struct {
 	deckID      string
+	cardID      string
 	filename    string
+	id          string
+	name        string
+	hash        string
+	identity    string
+	card        card.Card
 	req         mochi.CreateCardRequest
 	attachments []converter.Attachment
 }
@@ -21,12 +28,18 @@ func CreateCard(deckID string, card card.Card) Request {
 	return &createRequest{
 		deckID:   deckID,
 		filename: card.Filename(),
+		id:       card.ID,
+		name:     card.Fields["name"],
+		hash:     card.Hash(),
+		identity: cardIdentity(card.ID, card.Filename(), card.Fields["name"]),
+		card:     card,
 		req: mochi.CreateCardRequest{
-			Content:    card.Content,
-			DeckID:     deckID,
-			TemplateID: card.TemplateID,
-			Fields:     mochiFields(card.Fields),
-			Pos:        card.Position,
+			Content:       card.Content,
+			DeckID:        deckID,
+			TemplateID:    card.TemplateID,
+			ReviewReverse: card.ReviewReverse,
+			Fields:        mochiFields(card.Fields),
+			Pos:           card.Position,
 		},
 		attachments: card.Attachments,
 	}
@@ -39,6 +52,8 @@ func (r *createRequest) Execute(ctx context.Context, client Client, lf Lockfile)
 		return err
 	}
 
+	r.cardID = card.ID
+
 	for _, attachment := range r.attachments {
 		if err := client.AddAttachment(ctx, card.ID, attachment.Filename, attachment.Bytes); err != nil {
 			return err
@@ -48,13 +63,25 @@ func (r *createRequest) Execute(ctx context.Context, client Client, lf Lockfile)
 	lf.Lock()
 	defer lf.Unlock()
 
-	if err := lf.SetCard(r.deckID, card.ID, r.filename); err != nil {
+	if err := lf.SetCard(r.deckID, card.ID, lock.Card{Filename: r.filename, ID: r.id, Name: r.name, Hash: r.hash}); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Summary implements the Request interface.
+func (r *createRequest) Summary() Summary {
+	return Summary{
+		Kind:        KindCreate,
+		DeckID:      r.deckID,
+		CardID:      r.cardID,
+		Filename:    r.filename,
+		Path:        r.card.Path,
+		Attachments: len(r.attachments),
+	}
+}
+
 // String implements the fmt.Stringer interface.
 func (r *createRequest) String() string {
 	if len(r.attachments) > 0 {
@@ -3,15 +3,37 @@ package request
 import (
 	"context"
 	"fmt"
+
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/mochi"
 )
 
 type deleteCard struct {
-	cardID string
+	deckID      string
+	cardID      string
+	identity    string
+	attachments map[string]mochi.Attachment
+}
+
+// DeleteCard returns a new delete card request.
+func DeleteCard(deckID, cardID string) Request {
+	return &deleteCard{
+		deckID: deckID,
+		cardID: cardID,
+	}
 }
 
-// DeleteCard returns a new archive card request.
-func DeleteCard(cardID string) Request {
-	return &deleteCard{cardID: cardID}
+// DeleteTrackedCard returns a new delete card request for a card
+// tracked in the lockfile.
+//
+// Unlike DeleteCard, the request can be turned into a move by Moves.
+func DeleteTrackedCard(deckID string, mochiCard mochi.Card, lockCard lock.Card) Request {
+	return &deleteCard{
+		deckID:      deckID,
+		cardID:      mochiCard.ID,
+		identity:    cardIdentity(lockCard.ID, lockCard.Filename, mochiCard.Fields["name"].Value),
+		attachments: mochiCard.Attachments,
+	}
 }
 
 // Execute implements the Request interface.
@@ -19,6 +41,15 @@ func (r *deleteCard) Execute(ctx context.Context, client Client, _ Lockfile) err
 	return client.DeleteCard(ctx, r.cardID)
 }
 
+// Summary implements the Request interface.
+func (r *deleteCard) Summary() Summary {
+	return Summary{
+		Kind:   KindDelete,
+		DeckID: r.deckID,
+		CardID: r.cardID,
+	}
+}
+
 // String implements the fmt.Stringer interface.
 func (r *deleteCard) String() string {
 	return fmt.Sprintf("delete request for card ID %s", r.cardID)
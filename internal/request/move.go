@@ -0,0 +1,81 @@
+package request
+
+import (
+	"fmt"
+
+	"github.com/leonhfr/mochi/mochi"
+)
+
+// Moves replaces the delete and create requests of the cards that moved
+// between decks with update requests, which preserves their review history.
+//
+// Cards are paired by identity: their persistent ID if any, otherwise
+// their filename and name. Ambiguous identities are left untouched.
+func Moves(reqs []Request) []Request {
+	removals := make(map[string][]removal)
+	creates := make(map[string][]*createRequest)
+	for _, req := range reqs {
+		switch r := req.(type) {
+		case *deleteCard:
+			if r.identity != "" {
+				removals[r.identity] = append(removals[r.identity], removal{r, r.deckID, r.cardID, r.attachments})
+			}
+		case *archiveCard:
+			if r.identity != "" {
+				removals[r.identity] = append(removals[r.identity], removal{r, r.deckID, r.cardID, r.attachments})
+			}
+		case *createRequest:
+			if r.identity != "" {
+				creates[r.identity] = append(creates[r.identity], r)
+			}
+		}
+	}
+
+	moves := make(map[Request]Request)
+	for identity, removed := range removals {
+		created := creates[identity]
+		if len(removed) != 1 || len(created) != 1 || removed[0].deckID == created[0].deckID {
+			continue
+		}
+
+		move := MoveCard(created[0].deckID, removed[0].deckID, removed[0].cardID, created[0].card, removed[0].attachments)
+		moves[removed[0].req] = move
+		moves[created[0]] = nil
+	}
+
+	if len(moves) == 0 {
+		return reqs
+	}
+
+	result := make([]Request, 0, len(reqs)-len(moves)/2)
+	for _, req := range reqs {
+		move, ok := moves[req]
+		switch {
+		case !ok:
+			result = append(result, req)
+		case move != nil:
+			result = append(result, move)
+		}
+	}
+	return result
+}
+
+// removal represents a delete or archive request.
+type removal struct {
+	req         Request
+	deckID      string
+	cardID      string
+	attachments map[string]mochi.Attachment
+}
+
+// cardIdentity returns the identity used to detect moved cards.
+func cardIdentity(id, filename, name string) string {
+	switch {
+	case id != "":
+		return fmt.Sprintf("id:%s", id)
+	case filename != "" && name != "":
+		return fmt.Sprintf("name:%s/%s", filename, name)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,89 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/internal/card"
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/internal/parser"
+	"github.com/leonhfr/mochi/mochi"
+)
+
+func Test_Moves(t *testing.T) {
+	moved := card.Card{Card: parser.Card{
+		Content: "CONTENT",
+		Fields:  map[string]string{"name": "Note"},
+		Path:    "/b/note.md",
+	}}
+	identified := card.Card{Card: parser.Card{
+		ID:      "IDENTITY",
+		Content: "CONTENT",
+		Fields:  map[string]string{"name": "Renamed"},
+		Path:    "/b/other.md",
+	}}
+	sameDeck := card.Card{Card: parser.Card{
+		Content: "CONTENT",
+		Fields:  map[string]string{"name": "Same"},
+		Path:    "/a/same.md",
+	}}
+	attachments := map[string]mochi.Attachment{"image.png": {Size: 10}}
+
+	deleteMoved := DeleteTrackedCard("DECK_A", mochi.Card{
+		ID:          "CARD_ID_1",
+		Fields:      map[string]mochi.Field{"name": {ID: "name", Value: "Note"}},
+		Attachments: attachments,
+	}, lock.Card{Filename: "note.md"})
+	deleteIdentified := DeleteTrackedCard("DECK_A", mochi.Card{
+		ID:     "CARD_ID_2",
+		Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Note 2"}},
+	}, lock.Card{Filename: "note2.md", ID: "IDENTITY"})
+	deleteSameDeck := DeleteTrackedCard("DECK_A", mochi.Card{
+		ID:     "CARD_ID_3",
+		Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Same"}},
+	}, lock.Card{Filename: "same.md"})
+	deleteUntracked := DeleteCard("DECK_A", "CARD_ID_4")
+	createMoved := CreateCard("DECK_B", moved)
+	createIdentified := CreateCard("DECK_B", identified)
+	createSameDeck := CreateCard("DECK_A", sameDeck)
+
+	reqs := []Request{
+		deleteMoved,
+		deleteIdentified,
+		deleteSameDeck,
+		deleteUntracked,
+		createMoved,
+		createIdentified,
+		createSameDeck,
+	}
+	want := []Request{
+		MoveCard("DECK_B", "DECK_A", "CARD_ID_1", moved, attachments),
+		MoveCard("DECK_B", "DECK_A", "CARD_ID_2", identified, nil),
+		deleteSameDeck,
+		deleteUntracked,
+		createSameDeck,
+	}
+
+	got := Moves(reqs)
+	assert.Equal(t, want, got)
+}
+
+func Test_Moves_ambiguous(t *testing.T) {
+	note := card.Card{Card: parser.Card{
+		Content: "CONTENT",
+		Fields:  map[string]string{"name": "Note"},
+		Path:    "/b/note.md",
+	}}
+	reqs := []Request{
+		DeleteTrackedCard("DECK_A", mochi.Card{
+			ID:     "CARD_ID_1",
+			Fields: map[string]mochi.Field{"name": {ID: "name", Value: "Note"}},
+		}, lock.Card{Filename: "note.md"}),
+		CreateCard("DECK_B", note),
+		CreateCard("DECK_C", note),
+	}
+
+	got := Moves(reqs)
+	assert.Equal(t, reqs, got)
+}
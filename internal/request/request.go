@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/leonhfr/mochi/internal/lock"
 	"github.com/leonhfr/mochi/mochi"
 )
 
@@ -13,19 +14,44 @@ type Client interface {
 	UpdateCard(ctx context.Context, id string, req mochi.UpdateCardRequest) (mochi.Card, error)
 	DeleteCard(ctx context.Context, id string) error
 	AddAttachment(ctx context.Context, cardID, filename string, data []byte) error
+	DeleteAttachment(ctx context.Context, cardID, filename string) error
 }
 
 // Lockfile is the interface the lockfile implement to sync cards.
 type Lockfile interface {
 	Lock()
 	Unlock()
-	SetCard(deckID, cardID, filename string) error
+	SetCard(deckID, cardID string, card lock.Card) error
+	DeleteCard(deckID, cardID string)
 }
 
 // Request is the interface that should be implemented to execute a request.
 type Request interface {
 	fmt.Stringer
 	Execute(ctx context.Context, client Client, lf Lockfile) error
+	Summary() Summary
+}
+
+// Kind represents the kind of a request.
+type Kind string
+
+// Request kinds.
+const (
+	KindCreate  Kind = "create"
+	KindUpdate  Kind = "update"
+	KindDelete  Kind = "delete"
+	KindArchive Kind = "archive"
+)
+
+// Summary describes a request.
+type Summary struct {
+	Kind           Kind
+	DeckID         string
+	PreviousDeckID string // set when the card moves between decks
+	CardID         string // empty until a create request has been executed
+	Filename       string
+	Path           string // source file of the card, set by the create and update requests
+	Attachments    int
 }
 
 func mochiFields(fields map[string]string) map[string]mochi.Field {
@@ -0,0 +1,43 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrThresholdExceeded is the error returned when the destructive requests
+// exceed the deletion threshold.
+var ErrThresholdExceeded = errors.New("deletion threshold exceeded")
+
+// Destructive returns the number of requests that delete or archive a card.
+func Destructive(reqs []Request) int {
+	count := 0
+	for _, req := range reqs {
+		switch req.Summary().Kind {
+		case KindDelete, KindArchive:
+			count++
+		}
+	}
+	return count
+}
+
+// CheckThreshold returns an error wrapping ErrThresholdExceeded when the destructive
+// requests exceed either the absolute count or the percentage of tracked cards.
+//
+// A zero count or percentage disables the corresponding check.
+func CheckThreshold(reqs []Request, tracked, count int, percent float64) error {
+	destructive := Destructive(reqs)
+	if destructive == 0 {
+		return nil
+	}
+
+	if count > 0 && destructive > count {
+		return fmt.Errorf("%w: %d destructive requests, maximum %d", ErrThresholdExceeded, destructive, count)
+	}
+
+	if percent > 0 && (tracked == 0 || float64(destructive)*100 > percent*float64(tracked)) {
+		return fmt.Errorf("%w: %d destructive requests for %d tracked cards, maximum %g%%", ErrThresholdExceeded, destructive, tracked, percent)
+	}
+
+	return nil
+}
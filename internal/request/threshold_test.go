@@ -0,0 +1,82 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/internal/card"
+	"github.com/leonhfr/mochi/internal/parser"
+)
+
+func Test_CheckThreshold(t *testing.T) {
+	reqs := []Request{
+		DeleteCard("DECK_ID", "CARD_ID_1"),
+		ArchiveCard("DECK_ID", "CARD_ID_2"),
+		CreateCard("DECK_ID", card.Card{Card: parser.Card{Content: "CONTENT"}}),
+	}
+
+	tests := []struct {
+		name     string
+		reqs     []Request
+		tracked  int
+		count    int
+		percent  float64
+		exceeded bool
+	}{
+		{
+			name:    "disabled",
+			reqs:    reqs,
+			tracked: 2,
+		},
+		{
+			name:    "no destructive requests",
+			reqs:    reqs[2:],
+			count:   1,
+			percent: 1,
+		},
+		{
+			name:    "below count",
+			reqs:    reqs,
+			tracked: 10,
+			count:   2,
+		},
+		{
+			name:     "above count",
+			reqs:     reqs,
+			tracked:  10,
+			count:    1,
+			exceeded: true,
+		},
+		{
+			name:    "below percent",
+			reqs:    reqs,
+			tracked: 10,
+			percent: 20,
+		},
+		{
+			name:     "above percent",
+			reqs:     reqs,
+			tracked:  10,
+			percent:  10,
+			exceeded: true,
+		},
+		{
+			name:     "percent without tracked cards",
+			reqs:     reqs,
+			percent:  50,
+			exceeded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckThreshold(tt.reqs, tt.tracked, tt.count, tt.percent)
+			if tt.exceeded {
+				assert.ErrorIs(t, err, ErrThresholdExceeded)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -3,36 +3,65 @@ package request
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/leonhfr/mochi/internal/card"
 	"github.com/leonhfr/mochi/internal/converter"
+	"github.com/leonhfr/mochi/internal/lock"
 	"github.com/leonhfr/mochi/mochi"
 )
 
 type updateCard struct {
-	deckID      string
-	cardID      string
-	filename    string
-	req         mochi.UpdateCardRequest
-	attachments []converter.Attachment
+	deckID         string
+	previousDeckID string // set when the card moves between decks
+	cardID         string
+	filename       string
+	path           string
+	id             string
+	name           string
+	hash           string
+	req            mochi.UpdateCardRequest
+	attachments    []converter.Attachment
+	removed        []string // filenames of the attachments no longer referenced
 }
 
 // UpdateCard returns a new update card request.
-func UpdateCard(deckID, cardID string, card card.Card, attachments map[string]mochi.Attachment) Request {
+//
+// The card is un-archived when unarchive is set. Only the attachments
+// missing from the card are uploaded, and the attachments the card no
+// longer references are removed.
+func UpdateCard(deckID, cardID string, card card.Card, attachments map[string]mochi.Attachment, unarchive bool) Request {
 	return &updateCard{
 		deckID:   deckID,
 		cardID:   cardID,
 		filename: card.Filename(),
+		path:     card.Path,
+		id:       card.ID,
+		name:     card.Fields["name"],
+		hash:     card.Hash(),
 		req: mochi.UpdateCardRequest{
-			Content:    card.Content,
-			TemplateID: card.TemplateID,
-			Fields:     mochiFields(card.Fields),
-			Pos:        card.Position,
+			Content:              card.Content,
+			TemplateID:           card.TemplateID,
+			Unarchive:            unarchive,
+			ReviewReverse:        card.ReviewReverse,
+			DisableReviewReverse: !card.ReviewReverse,
+			Fields:               mochiFields(card.Fields),
+			Pos:                  card.Position,
 		},
 		attachments: filterAttachments(card.Attachments, attachments),
+		removed:     staleAttachments(card.Attachments, attachments),
 	}
 }
 
+// MoveCard returns a new update card request that moves the card
+// from its previous deck to deckID.
+func MoveCard(deckID, previousDeckID, cardID string, card card.Card, attachments map[string]mochi.Attachment) Request {
+	r := UpdateCard(deckID, cardID, card, attachments, false).(*updateCard)
+	r.previousDeckID = previousDeckID
+	r.req.DeckID = deckID
+	return r
+}
+
 // Execute implements the Request interface.
 func (r *updateCard) Execute(ctx context.Context, client Client, lf Lockfile) error {
 	if _, err := client.UpdateCard(ctx, r.cardID, r.req); err != nil {
@@ -45,28 +74,80 @@ func (r *updateCard) Execute(ctx context.Context, client Client, lf Lockfile) er
 		}
 	}
 
+	for _, filename := range r.removed {
+		if err := client.DeleteAttachment(ctx, r.cardID, filename); err != nil {
+			return err
+		}
+	}
+
 	lf.Lock()
 	defer lf.Unlock()
 
-	if err := lf.SetCard(r.deckID, r.cardID, r.filename); err != nil {
+	if err := lf.SetCard(r.deckID, r.cardID, lock.Card{Filename: r.filename, ID: r.id, Name: r.name, Hash: r.hash}); err != nil {
 		return err
 	}
 
+	if r.previousDeckID != "" {
+		lf.DeleteCard(r.previousDeckID, r.cardID)
+	}
+
 	return nil
 }
 
-func filterAttachments(images []converter.Attachment, mochiAttachments map[string]mochi.Attachment) []converter.Attachment {
-	attachments := []converter.Attachment{}
-	for _, image := range images {
-		if mochiAttachment, ok := mochiAttachments[image.Filename]; !ok || mochiAttachment.Size != len(image.Bytes) {
-			attachments = append(attachments, image)
+// Summary implements the Request interface.
+func (r *updateCard) Summary() Summary {
+	return Summary{
+		Kind:           KindUpdate,
+		DeckID:         r.deckID,
+		PreviousDeckID: r.previousDeckID,
+		CardID:         r.cardID,
+		Filename:       r.filename,
+		Path:           r.path,
+		Attachments:    len(r.attachments),
+	}
+}
+
+// filterAttachments returns the attachments missing from the mochi card.
+//
+// The filenames are derived from the content, so an attachment
+// with the same filename has the same content.
+func filterAttachments(attachments []converter.Attachment, mochiAttachments map[string]mochi.Attachment) []converter.Attachment {
+	filtered := []converter.Attachment{}
+	for _, attachment := range attachments {
+		_, uploaded := mochiAttachments[attachment.Filename]
+		duplicated := slices.ContainsFunc(filtered, func(a converter.Attachment) bool {
+			return a.Filename == attachment.Filename
+		})
+		if !uploaded && !duplicated {
+			filtered = append(filtered, attachment)
+		}
+	}
+	return filtered
+}
+
+// staleAttachments returns the sorted filenames of the mochi card
+// attachments that are no longer referenced.
+func staleAttachments(attachments []converter.Attachment, mochiAttachments map[string]mochi.Attachment) []string {
+	var stale []string
+	for filename := range mochiAttachments {
+		if !slices.ContainsFunc(attachments, func(a converter.Attachment) bool {
+			return a.Filename == filename
+		}) {
+			stale = append(stale, filename)
 		}
 	}
-	return attachments
+	slices.Sort(stale)
+	return stale
 }
 
 // String implements the fmt.Stringer interface.
 func (r *updateCard) String() string {
+	if r.previousDeckID != "" {
+		return fmt.Sprintf("move request for card ID %s (%s) from deck ID %s", r.cardID, r.filename, r.previousDeckID)
+	}
+	if len(r.removed) > 0 {
+		return fmt.Sprintf("update request for card ID %s (%s) with %d attachments, %d removed", r.cardID, r.filename, len(r.attachments), len(r.removed))
+	}
 	if len(r.attachments) > 0 {
 		return fmt.Sprintf("update request for card ID %s (%s) with %d attachments", r.cardID, r.filename, len(r.attachments))
 	}
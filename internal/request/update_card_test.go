@@ -0,0 +1,39 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/leonhfr/mochi/internal/converter"
+	"github.com/leonhfr/mochi/mochi"
+)
+
+func Test_filterAttachments(t *testing.T) {
+	attachments := []converter.Attachment{
+		{Bytes: []byte("A"), Filename: "a.png"},
+		{Bytes: []byte("B"), Filename: "b.png"},
+		{Bytes: []byte("B"), Filename: "b.png"},
+	}
+	mochiAttachments := map[string]mochi.Attachment{
+		"a.png": {Size: 1},
+		"c.png": {Size: 1},
+	}
+
+	got := filterAttachments(attachments, mochiAttachments)
+	assert.Equal(t, []converter.Attachment{{Bytes: []byte("B"), Filename: "b.png"}}, got)
+}
+
+func Test_staleAttachments(t *testing.T) {
+	attachments := []converter.Attachment{
+		{Bytes: []byte("A"), Filename: "a.png"},
+	}
+	mochiAttachments := map[string]mochi.Attachment{
+		"d.png": {Size: 1},
+		"a.png": {Size: 1},
+		"c.png": {Size: 1},
+	}
+
+	got := staleAttachments(attachments, mochiAttachments)
+	assert.Equal(t, []string{"c.png", "d.png"}, got)
+}
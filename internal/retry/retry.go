@@ -0,0 +1,234 @@
+package retry
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 5
+	defaultBaseDelay  = 500 * time.Millisecond
+	defaultMaxDelay   = 30 * time.Second
+)
+
+var _ http.RoundTripper = &Transport{}
+
+var retryableStatus = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// Logger is the interface to log the retries.
+type Logger interface {
+	Infof(format string, args ...any)
+}
+
+// Transport is a transport that retries the requests that failed with
+// a transient error and implements the http.RoundTripper interface.
+//
+// Requests rejected with 429 Too Many Requests are always retried since
+// they have not been processed. Requests that failed with a 5xx status
+// or a network error are only retried when idempotent.
+type Transport struct {
+	rt         http.RoundTripper
+	logger     Logger
+	idempotent func(*http.Request) bool
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	retries    atomic.Int64
+}
+
+// Option is a Transport option.
+type Option func(*Transport)
+
+// New returns a new retrying transport.
+func New(options ...Option) *Transport {
+	transport := &Transport{
+		rt:         http.DefaultTransport,
+		idempotent: idempotentMethod,
+		maxRetries: defaultMaxRetries,
+		baseDelay:  defaultBaseDelay,
+		maxDelay:   defaultMaxDelay,
+	}
+	for _, option := range options {
+		option(transport)
+	}
+	return transport
+}
+
+// WithTransport sets a http.RoundTripper that replaces http.DefaultTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(t *Transport) {
+		t.rt = rt
+	}
+}
+
+// WithLogger sets the logger that reports each retry.
+func WithLogger(logger Logger) Option {
+	return func(t *Transport) {
+		t.logger = logger
+	}
+}
+
+// WithIdempotent sets the function that reports whether a request
+// can safely be repeated. Defaults to the idempotent HTTP methods.
+func WithIdempotent(idempotent func(*http.Request) bool) Option {
+	return func(t *Transport) {
+		t.idempotent = idempotent
+	}
+}
+
+// WithMaxRetries sets the maximum number of retries of a request.
+func WithMaxRetries(maxRetries int) Option {
+	return func(t *Transport) {
+		t.maxRetries = maxRetries
+	}
+}
+
+// WithBackoff sets the base and maximum delays of the exponential backoff.
+func WithBackoff(base, maxDelay time.Duration) Option {
+	return func(t *Transport) {
+		t.baseDelay = base
+		t.maxDelay = maxDelay
+	}
+}
+
+// Retries returns the total number of retries.
+func (t *Transport) Retries() int64 {
+	return t.retries.Load()
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := rewind(r, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := t.rt.RoundTrip(req)
+		if attempt >= t.maxRetries || !t.retryable(r, res, err) {
+			return res, err
+		}
+
+		delay := t.delay(attempt, res)
+		t.retries.Add(1)
+		if t.logger != nil {
+			t.logger.Infof("retry(%s %s): attempt %d/%d in %s: %s", r.Method, r.URL.Path, attempt+1, t.maxRetries, delay.Round(time.Millisecond), reason(res, err))
+		}
+
+		if res != nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return nil, r.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether the request can be retried.
+//
+// A request whose body cannot be read again is never retried.
+func (t *Transport) retryable(r *http.Request, res *http.Response, err error) bool {
+	if r.Body != nil && r.Body != http.NoBody && r.GetBody == nil {
+		return false
+	}
+
+	if r.Context().Err() != nil {
+		return false
+	}
+
+	switch {
+	case err != nil:
+		return t.idempotent(r)
+	case res.StatusCode == http.StatusTooManyRequests:
+		return true
+	case slices.Contains(retryableStatus, res.StatusCode):
+		return t.idempotent(r)
+	default:
+		return false
+	}
+}
+
+// delay returns the delay before the next attempt.
+//
+// The Retry-After header is honoured when present, otherwise
+// the delay is a jittered exponential backoff.
+func (t *Transport) delay(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if delay, ok := retryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			return min(delay, t.maxDelay)
+		}
+	}
+
+	backoff := t.baseDelay << attempt
+	if backoff <= 0 || backoff > t.maxDelay {
+		backoff = t.maxDelay
+	}
+
+	//nolint:gosec
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// rewind returns the request to send for an attempt, with a fresh body for the retries.
+func rewind(r *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || r.GetBody == nil {
+		return r, nil
+	}
+
+	body, err := r.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	req := r.Clone(r.Context())
+	req.Body = body
+	return req, nil
+}
+
+func idempotentMethod(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func reason(res *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return res.Status
+}
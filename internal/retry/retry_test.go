@@ -0,0 +1,202 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Transport_RoundTrip(t *testing.T) {
+	errNetwork := errors.New("network error")
+
+	tests := []struct {
+		name      string
+		method    string
+		responses []response
+		status    int
+		err       error
+		attempts  int
+	}{
+		{
+			name:      "success",
+			method:    http.MethodGet,
+			responses: []response{{status: http.StatusOK}},
+			status:    http.StatusOK,
+			attempts:  1,
+		},
+		{
+			name:      "too many requests",
+			method:    http.MethodPost,
+			responses: []response{{status: http.StatusTooManyRequests}, {status: http.StatusOK}},
+			status:    http.StatusOK,
+			attempts:  2,
+		},
+		{
+			name:      "server error on idempotent request",
+			method:    http.MethodGet,
+			responses: []response{{status: http.StatusBadGateway}, {err: errNetwork}, {status: http.StatusOK}},
+			status:    http.StatusOK,
+			attempts:  3,
+		},
+		{
+			name:      "server error on non idempotent request",
+			method:    http.MethodPost,
+			responses: []response{{status: http.StatusBadGateway}},
+			status:    http.StatusBadGateway,
+			attempts:  1,
+		},
+		{
+			name:      "client error",
+			method:    http.MethodGet,
+			responses: []response{{status: http.StatusBadRequest}},
+			status:    http.StatusBadRequest,
+			attempts:  1,
+		},
+		{
+			name:      "max retries",
+			method:    http.MethodGet,
+			responses: []response{{status: http.StatusServiceUnavailable}, {status: http.StatusServiceUnavailable}, {status: http.StatusServiceUnavailable}},
+			status:    http.StatusServiceUnavailable,
+			attempts:  3,
+		},
+		{
+			name:      "network error on non idempotent request",
+			method:    http.MethodPost,
+			responses: []response{{err: errNetwork}},
+			err:       errNetwork,
+			attempts:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &mockTransport{responses: tt.responses}
+			transport := New(WithTransport(rt), WithMaxRetries(2), WithBackoff(time.Millisecond, time.Millisecond))
+
+			req, err := http.NewRequest(tt.method, "https://example.com/api/cards", bytes.NewBufferString("BODY"))
+			require.NoError(t, err)
+
+			res, err := transport.RoundTrip(req)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.status, res.StatusCode)
+			}
+			assert.Equal(t, tt.attempts, len(rt.bodies))
+			assert.Equal(t, int64(tt.attempts-1), transport.Retries())
+			for _, body := range rt.bodies {
+				assert.Equal(t, "BODY", body)
+			}
+		})
+	}
+}
+
+func Test_Transport_RoundTrip_unreadableBody(t *testing.T) {
+	rt := &mockTransport{responses: []response{{status: http.StatusTooManyRequests}}}
+	transport := New(WithTransport(rt), WithBackoff(time.Millisecond, time.Millisecond))
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/api/cards", io.NopCloser(strings.NewReader("BODY")))
+	require.NoError(t, err)
+
+	res, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, 1, len(rt.bodies))
+}
+
+func Test_Transport_RoundTrip_canceled(t *testing.T) {
+	rt := &mockTransport{responses: []response{{status: http.StatusTooManyRequests, retryAfter: "60"}}}
+	transport := New(WithTransport(rt), WithBackoff(time.Minute, time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/api/cards", nil)
+	require.NoError(t, err)
+
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_retryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{name: "empty"},
+		{name: "seconds", header: "3", want: 3 * time.Second, ok: true},
+		{name: "date", header: "Mon, 01 Jan 2024 00:00:05 GMT", want: 5 * time.Second, ok: true},
+		{name: "past date", header: "Sun, 31 Dec 2023 23:59:00 GMT", want: 0, ok: true},
+		{name: "invalid", header: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.header, now)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func Test_Transport_delay(t *testing.T) {
+	transport := New(WithBackoff(100*time.Millisecond, time.Second))
+
+	for attempt := 0; attempt < 6; attempt++ {
+		backoff := min(100*time.Millisecond<<attempt, time.Second)
+		got := transport.delay(attempt, nil)
+		assert.GreaterOrEqual(t, got, backoff/2)
+		assert.LessOrEqual(t, got, backoff)
+	}
+
+	res := &http.Response{Header: http.Header{"Retry-After": {"10"}}}
+	assert.Equal(t, time.Second, transport.delay(0, res))
+}
+
+type response struct {
+	status     int
+	retryAfter string
+	err        error
+}
+
+type mockTransport struct {
+	responses []response
+	bodies    []string
+}
+
+func (m *mockTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body := ""
+	if r.Body != nil {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}
+	m.bodies = append(m.bodies, body)
+
+	res := m.responses[min(len(m.bodies), len(m.responses))-1]
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	header := http.Header{}
+	if res.retryAfter != "" {
+		header.Set("Retry-After", res.retryAfter)
+	}
+	return &http.Response{
+		Status:     http.StatusText(res.status),
+		StatusCode: res.status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader("")),
+	}, nil
+}
@@ -13,6 +13,13 @@ type ConverterCall struct {
 	Err    error
 }
 
+type ConverterAudioCall struct {
+	Path   string
+	Name   string
+	Result converter.Result
+	Ok     bool
+}
+
 type MockConverter struct {
 	mock.Mock
 }
@@ -27,7 +34,21 @@ func NewMockConverter(calls []ConverterCall) *MockConverter {
 	return m
 }
 
+func (m *MockConverter) WithAudio(calls []ConverterAudioCall) *MockConverter {
+	for _, call := range calls {
+		m.
+			On("ConvertAudio", mock.Anything, call.Path, call.Name).
+			Return(call.Result, call.Ok)
+	}
+	return m
+}
+
 func (m *MockConverter) Convert(reader converter.Reader, path, source string) (converter.Result, error) {
 	args := m.Called(reader, path, source)
 	return args.Get(0).(converter.Result), args.Error(1)
 }
+
+func (m *MockConverter) ConvertAudio(reader converter.Reader, path, name string) (converter.Result, bool) {
+	args := m.Called(reader, path, name)
+	return args.Get(0).(converter.Result), args.Bool(1)
+}
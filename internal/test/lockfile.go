@@ -12,9 +12,12 @@ type Lockfile struct {
 	Decks        []map[string]lock.Deck
 	DeckFromPath []LockfileDeckFromPath
 	SetDeck      []LockfileSetDeck
+	SetVirtual   []LockfileSetVirtualDeck
 	DeleteDeck   []string
 	UpdateDeck   []LockfileUpdateDeck
 	DeleteCard   []LockfileDeleteCard
+	Card         []LockfileCard
+	SetCard      []LockfileSetCard
 }
 
 type LockfileDeck struct {
@@ -37,11 +40,31 @@ type LockfileSetDeck struct {
 	Name     string
 }
 
+type LockfileSetVirtualDeck struct {
+	ID       string
+	ParentID string
+	Name     string
+}
+
 type LockfileUpdateDeck struct {
 	ID   string
 	Name string
 }
 
+type LockfileCard struct {
+	DeckID string
+	CardID string
+	Card   lock.Card
+	OK     bool
+}
+
+type LockfileSetCard struct {
+	DeckID string
+	CardID string
+	Card   lock.Card
+	Err    error
+}
+
 type LockfileDeleteCard struct {
 	DeckID string
 	CardID string
@@ -65,6 +88,9 @@ func NewMockLockfile(calls Lockfile) *MockLockfile {
 	for _, call := range calls.SetDeck {
 		lf.On("SetDeck", call.ID, call.ParentID, call.Path, call.Name).Return()
 	}
+	for _, call := range calls.SetVirtual {
+		lf.On("SetVirtualDeck", call.ID, call.ParentID, call.Name).Return()
+	}
 	for _, call := range calls.UpdateDeck {
 		lf.On("UpdateDeck", call.ID, call.Name).Return()
 	}
@@ -74,6 +100,12 @@ func NewMockLockfile(calls Lockfile) *MockLockfile {
 	for _, call := range calls.DeleteCard {
 		lf.On("DeleteCard", call.DeckID, call.CardID).Return()
 	}
+	for _, call := range calls.Card {
+		lf.On("Card", call.DeckID, call.CardID).Return(call.Card, call.OK)
+	}
+	for _, call := range calls.SetCard {
+		lf.On("SetCard", call.DeckID, call.CardID, call.Card).Return(call.Err)
+	}
 	return lf
 }
 
@@ -108,6 +140,10 @@ func (m *MockLockfile) SetDeck(id, parentID, path, name string) {
 	m.Called(id, parentID, path, name)
 }
 
+func (m *MockLockfile) SetVirtualDeck(id, parentID, name string) {
+	m.Called(id, parentID, name)
+}
+
 func (m *MockLockfile) UpdateDeck(id, name string) {
 	m.Called(id, name)
 }
@@ -119,3 +155,13 @@ func (m *MockLockfile) DeleteDeck(id string) {
 func (m *MockLockfile) DeleteCard(deckID, cardID string) {
 	m.Called(deckID, cardID)
 }
+
+func (m *MockLockfile) Card(deckID, cardID string) (lock.Card, bool) {
+	args := m.Called(deckID, cardID)
+	return args.Get(0).(lock.Card), args.Bool(1)
+}
+
+func (m *MockLockfile) SetCard(deckID, cardID string, card lock.Card) error {
+	args := m.Called(deckID, cardID, card)
+	return args.Error(0)
+}
@@ -12,8 +12,9 @@ import (
 var ErrMochi = errors.New("mochi error")
 
 type Mochi struct {
-	CreateDeck []MochiCreateDeck
-	UpdateDeck []MochiUpdateDeck
+	CreateDeck      []MochiCreateDeck
+	UpdateDeck      []MochiUpdateDeck
+	ListCardsInDeck []MochiListCardsInDeck
 }
 
 type MochiCreateDeck struct {
@@ -29,6 +30,12 @@ type MochiUpdateDeck struct {
 	Err  error
 }
 
+type MochiListCardsInDeck struct {
+	DeckID string
+	Cards  []mochi.Card
+	Err    error
+}
+
 type MockMochi struct {
 	mock.Mock
 }
@@ -45,6 +52,11 @@ func NewMockMochi(calls Mochi) *MockMochi {
 			On("UpdateDeck", mock.Anything, call.ID, call.Req).
 			Return(call.Deck, call.Err)
 	}
+	for _, call := range calls.ListCardsInDeck {
+		m.
+			On("ListCardsInDeck", mock.Anything, call.DeckID).
+			Return(call.Cards, call.Err)
+	}
 	return m
 }
 
@@ -57,3 +69,8 @@ func (m *MockMochi) UpdateDeck(ctx context.Context, id string, req mochi.UpdateD
 	args := m.Called(ctx, id, req)
 	return args.Get(0).(mochi.Deck), args.Error(1)
 }
+
+func (m *MockMochi) ListCardsInDeck(ctx context.Context, id string) ([]mochi.Card, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]mochi.Card), args.Error(1)
+}
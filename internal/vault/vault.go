@@ -0,0 +1,84 @@
+// Package vault finds the files of a workspace by name.
+package vault
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Walker is the interface to walk the workspace.
+type Walker interface {
+	Walk(workspace string, extensions []string, cb func(path string)) error
+}
+
+// Vault represents the index of the files of a workspace.
+type Vault struct {
+	workspace string
+	files     map[string][]string // map[lowercase filename]paths relative to the workspace
+}
+
+// New indexes the files of the workspace matching the extensions.
+func New(w Walker, workspace string, extensions []string) (*Vault, error) {
+	v := &Vault{
+		workspace: workspace,
+		files:     map[string][]string{},
+	}
+
+	err := w.Walk(workspace, extensions, func(path string) {
+		path = filepath.Join("/", path)
+		name := strings.ToLower(filepath.Base(path))
+		v.files[name] = append(v.files[name], path)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, paths := range v.files {
+		slices.SortFunc(paths, func(a, b string) int {
+			return cmp.Or(
+				cmp.Compare(strings.Count(a, "/"), strings.Count(b, "/")),
+				strings.Compare(a, b),
+			)
+		})
+	}
+
+	return v, nil
+}
+
+// Find returns the absolute path of the file named from the note at path.
+//
+// The name may contain directories to disambiguate files sharing a name.
+// The file relative to the note is preferred, then the files in the same
+// directory as the note, then the files closest to the workspace root.
+//
+// Implements the converter.Vault interface.
+func (v *Vault) Find(path, name string) (string, bool) {
+	name = filepath.ToSlash(name)
+	dir := filepath.Join("/", strings.TrimPrefix(filepath.Dir(path), v.workspace))
+	candidates := v.files[strings.ToLower(filepath.Base(name))]
+
+	relative := filepath.Join(dir, name)
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, relative) {
+			return filepath.Join(v.workspace, candidate), true
+		}
+	}
+
+	suffix := strings.ToLower(filepath.Join("/", name))
+	candidates = slices.DeleteFunc(slices.Clone(candidates), func(candidate string) bool {
+		return !strings.HasSuffix(strings.ToLower(candidate), suffix)
+	})
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	for _, candidate := range candidates {
+		if filepath.Dir(candidate) == dir {
+			return filepath.Join(v.workspace, candidate), true
+		}
+	}
+
+	return filepath.Join(v.workspace, candidates[0]), true
+}
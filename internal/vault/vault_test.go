@@ -0,0 +1,98 @@
+package vault
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Vault_Find(t *testing.T) {
+	w := testWalker{
+		"/cat.png",
+		"/notes/cat.png",
+		"/notes/deep/Cat.png",
+		"/other/images/dog.png",
+		"/zoo/images/dog.png",
+		"/notes/Physics.md",
+	}
+
+	tests := []struct {
+		name string
+		path string
+		file string
+		want string
+		ok   bool
+	}{
+		{
+			name: "relative to the note",
+			path: "/workspace/notes/Note.md",
+			file: "deep/cat.png",
+			want: "/workspace/notes/deep/Cat.png",
+			ok:   true,
+		},
+		{
+			name: "same directory",
+			path: "/workspace/notes/Note.md",
+			file: "cat.png",
+			want: "/workspace/notes/cat.png",
+			ok:   true,
+		},
+		{
+			name: "closest to the root",
+			path: "/workspace/journal/Note.md",
+			file: "cat.png",
+			want: "/workspace/cat.png",
+			ok:   true,
+		},
+		{
+			name: "anywhere in the vault",
+			path: "/workspace/journal/Note.md",
+			file: "dog.png",
+			want: "/workspace/other/images/dog.png",
+			ok:   true,
+		},
+		{
+			name: "directory suffix",
+			path: "/workspace/journal/Note.md",
+			file: "zoo/images/dog.png",
+			want: "/workspace/zoo/images/dog.png",
+			ok:   true,
+		},
+		{
+			name: "note",
+			path: "/workspace/Index.md",
+			file: "physics.md",
+			want: "/workspace/notes/Physics.md",
+			ok:   true,
+		},
+		{
+			name: "not found",
+			path: "/workspace/Index.md",
+			file: "bird.png",
+		},
+		{
+			name: "directory mismatch",
+			path: "/workspace/Index.md",
+			file: "farm/dog.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := New(w, "/workspace", []string{".png", ".md"})
+			assert.NoError(t, err)
+			got, ok := v.Find(tt.path, tt.file)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+type testWalker []string
+
+func (w testWalker) Walk(_ string, _ []string, cb func(path string)) error {
+	for _, path := range w {
+		cb(path)
+	}
+	return nil
+}
@@ -2,8 +2,12 @@ package worker
 
 import (
 	"context"
+	"path/filepath"
+	"slices"
 
+	"github.com/leonhfr/mochi/internal/config"
 	"github.com/leonhfr/mochi/internal/heap"
+	"github.com/leonhfr/mochi/internal/parser"
 )
 
 // Logger is the interface to log output.
@@ -19,19 +23,31 @@ type Walker interface {
 
 // FileWalk is the worker that recursively walks directories and outputs them by
 // priority (shorter base directory length).
-func FileWalk(ctx context.Context, logger Logger, walker Walker, workspace string, extensions []string) (<-chan heap.Group[heap.Path], error) {
+//
+// The ignored paths are relative to the workspace. The dirs are output even
+// when they no longer contain any file, so that the cards of the decks whose
+// files all moved or vanished are removed.
+func FileWalk(ctx context.Context, logger Logger, walker Walker, workspace string, extensions, ignored, dirs []string) (<-chan heap.Group[heap.Path], error) {
 	h := heap.New[heap.Path]()
 
 	if err := walker.Walk(
 		workspace,
 		extensions,
-		func(path string) { h.Push(heap.Path(path)) },
+		func(path string) {
+			if !slices.Contains(ignored, path) {
+				h.Push(heap.Path(path))
+			}
+		},
 	); err != nil {
 		out := make(chan heap.Group[heap.Path])
 		close(out)
 		return out, err
 	}
 
+	for _, dir := range dirs {
+		h.PushEmpty(dir, heap.DirPriority(dir))
+	}
+
 	logger.Infof("filewalk: found %d directories", h.Len())
 
 	out := make(chan heap.Group[heap.Path])
@@ -49,3 +65,17 @@ func FileWalk(ctx context.Context, logger Logger, walker Walker, workspace strin
 
 	return out, nil
 }
+
+// DeckPaths returns the paths of the files the deck parses.
+//
+// The data files are only parsed by the decks opting in,
+// so that the manifests lying in the other decks are ignored.
+func DeckPaths(deck config.Deck, items []heap.Path) []string {
+	paths := heap.ConvertPaths(items)
+	if deck.DataFiles {
+		return paths
+	}
+	return slices.DeleteFunc(paths, func(path string) bool {
+		return slices.Contains(parser.DataExtensions(), filepath.Ext(path))
+	})
+}
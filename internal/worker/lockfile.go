@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+
+	"github.com/sourcegraph/conc/pool"
+
+	"github.com/leonhfr/mochi/internal/config"
+	"github.com/leonhfr/mochi/internal/deck"
+	"github.com/leonhfr/mochi/internal/lock"
+)
+
+// LoadClient is the interface the client should implement to load the lockfile.
+type LoadClient interface {
+	deck.CleanDecksClient
+	deck.CleanCardsClient
+}
+
+// LoadLockfile parses the lockfile and removes the decks that no longer exist.
+//
+// The cards of every deck are only checked when full is set, otherwise
+// they are checked when their deck is synced.
+func LoadLockfile(ctx context.Context, logger Logger, client LoadClient, rw lock.ReaderWriter, workspace string, full bool) (*lock.Lock, error) {
+	lf, err := lock.Parse(rw, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	err = deck.CleanDecks(ctx, client, lf)
+	if err != nil {
+		return nil, err
+	}
+
+	if full {
+		p := pool.New().WithErrors().WithContext(ctx)
+		for _, id := range getDeckIDs(lf.Decks()) {
+			id := id
+			p.Go(func(ctx context.Context) error {
+				return deck.CleanCards(ctx, client, lf, id)
+			})
+		}
+		if err := p.Wait(); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Infof("loaded lockfile")
+	logger.Debugf("lockfile: %v", lf.String())
+
+	return lf, nil
+}
+
+// DirsLockfile is the interface the lockfile should implement to list the deck directories.
+type DirsLockfile interface {
+	Lock()
+	Unlock()
+	Decks() map[string]lock.Deck
+}
+
+// LockfileDirs returns the sorted directories of the decks of the lockfile.
+func LockfileDirs(lf DirsLockfile) []string {
+	lf.Lock()
+	defer lf.Unlock()
+
+	var dirs []string
+	for _, deck := range lf.Decks() {
+		if !deck.Virtual && deck.Path != "" {
+			dirs = append(dirs, deck.Path)
+		}
+	}
+	slices.Sort(dirs)
+	return dirs
+}
+
+// IgnoredFiles returns the workspace files that are never parsed.
+func IgnoredFiles() []string {
+	ignored := []string{filepath.Join("/", lock.Filename)}
+	for _, name := range config.Filenames() {
+		ignored = append(ignored, filepath.Join("/", name))
+	}
+	return ignored
+}
+
+func getDeckIDs(decks map[string]lock.Deck) []string {
+	ids := make([]string, 0, len(decks))
+	for id := range decks {
+		ids = append(ids, id)
+	}
+	return ids
+}
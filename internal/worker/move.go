@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/leonhfr/mochi/internal/request"
+)
+
+// MoveRequests buffers the sync requests and replaces the delete and create
+// requests of cards that moved between decks with update requests.
+func MoveRequests(ctx context.Context, logger Logger, in <-chan request.Request) <-chan Result[request.Request] {
+	out := make(chan Result[request.Request], inflightRequests)
+	go func() {
+		defer close(out)
+
+		var reqs []request.Request
+		for req := range in {
+			reqs = append(reqs, req)
+		}
+
+		moved := request.Moves(reqs)
+		logger.Infof("move: %d cards moved between decks", len(reqs)-len(moved))
+		for _, req := range moved {
+			select {
+			case out <- Result[request.Request]{data: req}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
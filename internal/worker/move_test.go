@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonhfr/mochi/internal/config"
+	"github.com/leonhfr/mochi/internal/converter"
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/internal/parser"
+	"github.com/leonhfr/mochi/internal/request"
+	"github.com/leonhfr/mochi/internal/test"
+	"github.com/leonhfr/mochi/mochi"
+)
+
+func Test_MoveRequests_emptyDirectory(t *testing.T) {
+	workspace := "/workspace"
+	files := testFS{"/workspace/b/note.md": "Content."}
+	cfg := &config.Config{
+		RootName: "Root",
+		SkipRoot: true,
+		Deletion: config.DeletionDelete,
+		Decks: []config.Deck{
+			{Path: "/a", Name: "A", Deletion: config.DeletionDelete},
+			{Path: "/b", Name: "B", Deletion: config.DeletionDelete},
+		},
+	}
+
+	lf := lock.New(files, workspace)
+	lf.SetDeck("DECK_A", "", "/a", "A")
+	lf.SetDeck("DECK_B", "", "/b", "B")
+	require.NoError(t, lf.SetCard("DECK_A", "CARD_1", lock.Card{Filename: "note.md", Name: "note"}))
+
+	client := test.NewMockMochi(test.Mochi{
+		ListCardsInDeck: []test.MochiListCardsInDeck{
+			{DeckID: "DECK_A", Cards: []mochi.Card{{
+				ID:     "CARD_1",
+				DeckID: "DECK_A",
+				Fields: map[string]mochi.Field{"name": {ID: "name", Value: "note"}},
+			}}},
+			{DeckID: "DECK_B", Cards: []mochi.Card{}},
+		},
+	})
+
+	p, err := parser.New()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	logger := testLogger{}
+	wg := &sync.WaitGroup{}
+	errC := make(chan error)
+	go func() {
+		for err := range errC {
+			t.Error(err)
+		}
+	}()
+
+	dirC, err := FileWalk(ctx, logger, files, workspace, p.Extensions(), IgnoredFiles(), LockfileDirs(lf))
+	require.NoError(t, err)
+	deckC := Unwrap(wg, SyncDecks(ctx, logger, files, p, converter.New(), nil, client, cfg, lf, workspace, false, dirC), errC)
+	syncC := Unwrap(wg, SyncRequests(ctx, logger, client, lf, false, deckC), errC)
+	moveC := Unwrap(wg, MoveRequests(ctx, logger, syncC), errC)
+
+	var reqs []request.Request
+	for req := range moveC {
+		reqs = append(reqs, req)
+	}
+	wg.Wait()
+	close(errC)
+
+	require.Len(t, reqs, 1)
+	assert.Equal(t, request.Summary{
+		Kind:           request.KindUpdate,
+		DeckID:         "DECK_B",
+		PreviousDeckID: "DECK_A",
+		CardID:         "CARD_1",
+		Filename:       "note.md",
+		Path:           "/workspace/b/note.md",
+	}, reqs[0].Summary())
+	client.AssertExpectations(t)
+}
+
+type testFS map[string]string
+
+func (f testFS) Walk(workspace string, extensions []string, cb func(string)) error {
+	paths := make([]string, 0, len(f))
+	for path := range f {
+		if slices.Contains(extensions, filepath.Ext(path)) {
+			paths = append(paths, strings.TrimPrefix(path, workspace))
+		}
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		cb(path)
+	}
+	return nil
+}
+
+func (f testFS) Read(path string) (io.ReadCloser, error) {
+	content, ok := f[path]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func (f testFS) Write(string) (io.WriteCloser, error) {
+	return nil, fs.ErrPermission
+}
+
+type testLogger struct{}
+
+func (testLogger) Debugf(string, ...any) {}
+func (testLogger) Infof(string, ...any)  {}
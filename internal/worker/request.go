@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"time"
 
 	"github.com/sourcegraph/conc/stream"
 
@@ -10,8 +11,22 @@ import (
 
 const inflightRequests = 50
 
+// Recorder is the interface that should be implemented to record executed requests.
+type Recorder interface {
+	Record(summary request.Summary, duration time.Duration, err error)
+}
+
+// RequestLockfile is the interface the lockfile should implement to execute the requests.
+type RequestLockfile interface {
+	request.Lockfile
+	ResetFiles(deckID string)
+}
+
 // ExecuteRequests executes the sync requests.
-func ExecuteRequests(ctx context.Context, logger Logger, client request.Client, lf request.Lockfile, in <-chan request.Request) <-chan Result[struct{}] {
+//
+// The directory of a deck is synced again on the next run
+// when one of its requests fails.
+func ExecuteRequests(ctx context.Context, logger Logger, client request.Client, lf RequestLockfile, recorder Recorder, in <-chan request.Request) <-chan Result[struct{}] {
 	out := make(chan Result[struct{}])
 	go func() {
 		defer close(out)
@@ -21,7 +36,15 @@ func ExecuteRequests(ctx context.Context, logger Logger, client request.Client,
 			req := req
 			s.Go(func() stream.Callback {
 				logger.Infof("executing: %s", req.String())
-				if err := req.Execute(ctx, client, lf); err != nil {
+				start := time.Now()
+				err := req.Execute(ctx, client, lf)
+				recorder.Record(req.Summary(), time.Since(start), err)
+				if err != nil {
+					summary := req.Summary()
+					resetFiles(lf, summary.DeckID)
+					if summary.PreviousDeckID != "" {
+						resetFiles(lf, summary.PreviousDeckID)
+					}
 					return func() {
 						out <- Result[struct{}]{err: err}
 					}
@@ -2,10 +2,14 @@ package worker
 
 import (
 	"context"
+	"maps"
+	"path/filepath"
+	"slices"
 
 	"github.com/sourcegraph/conc/stream"
 
 	"github.com/leonhfr/mochi/internal/card"
+	"github.com/leonhfr/mochi/internal/config"
 	"github.com/leonhfr/mochi/internal/deck"
 	"github.com/leonhfr/mochi/internal/heap"
 	"github.com/leonhfr/mochi/internal/parser"
@@ -15,16 +19,53 @@ import (
 
 // Deck contains a synced deck.
 type Deck struct {
-	deckID string
-	name   string
-	cards  []card.Card
+	deckID   string
+	name     string
+	deletion config.Deletion
+	cards    []card.Card
+}
+
+// DeckLockfile is the interface the lockfile should implement to sync the decks.
+type DeckLockfile interface {
+	deck.CreateLockfile
+	deck.UnchangedLockfile
+	SetFiles(deckID string, files map[string]string)
+}
+
+// DeckConfig is the interface the config should implement to sync the decks.
+type DeckConfig interface {
+	deck.CreateConfig
+	Fingerprint(deck config.Deck) string
+}
+
+// DeckParser is the interface the parser should implement to sync the decks.
+type DeckParser interface {
+	card.Parser
+	Source(path string) ([]byte, bool)
+	Fingerprint() string
+}
+
+// LinkIndex is the interface the link index should implement
+// to resolve the links to the parsed cards.
+type LinkIndex interface {
+	Add(path string, names []string)
 }
 
 // SyncDecks syncs the decks and parses the files.
-func SyncDecks(ctx context.Context, logger Logger, r parser.Reader, p card.Parser, c card.Converter, client deck.CreateClient, config deck.CreateConfig, lf deck.CreateLockfile, workspace string, in <-chan heap.Group[heap.Path]) <-chan Result[Deck] {
+//
+// Unless full is set, the directories are skipped when their source files,
+// the files read during their conversion, their config and the plugin
+// executables did not change since the last sync.
+//
+// All the directories are parsed and added to the link index before the
+// cards are converted, so that the links between notes resolve across the
+// workspace. The directories linking to cards not synced yet are parsed
+// again on the next sync.
+func SyncDecks(ctx context.Context, logger Logger, r parser.Reader, p DeckParser, c card.Converter, links LinkIndex, client deck.CreateClient, config DeckConfig, lf DeckLockfile, workspace string, full bool, in <-chan heap.Group[heap.Path]) <-chan Result[Deck] {
 	out := make(chan Result[Deck])
 	go func() {
 		defer close(out)
+		var decks []parsedDeck
 		for group := range in {
 			deckConfig, ok := config.Deck(group.Base)
 			if !ok {
@@ -39,23 +80,85 @@ func SyncDecks(ctx context.Context, logger Logger, r parser.Reader, p card.Parse
 				continue
 			}
 
-			logger.Infof("parse(%s): parsing %d files", group.Base, len(group.Items))
-			filePaths := heap.ConvertPaths(group.Items)
-			cards, err := card.Parse(r, p, c, workspace, deckConfig.Parser, filePaths)
+			filePaths := DeckPaths(deckConfig, group.Items)
+			fingerprint := config.Fingerprint(deckConfig) + ":" + p.Fingerprint()
+			sources, err := card.HashFiles(r, workspace, fingerprint, filePaths)
+			if err != nil {
+				out <- Result[Deck]{err: err}
+				continue
+			}
+
+			files := maps.Clone(sources)
+			maps.Copy(files, card.HashDependencies(r, workspace, deck.Dependencies(lf, deckID)))
+			if !full && deck.FilesUnchanged(lf, deckID, files) {
+				logger.Infof("parse(%s): %d files unchanged, skipping", group.Base, len(filePaths))
+				continue
+			}
+
+			logger.Infof("parse(%s): parsing %d files", group.Base, len(filePaths))
+			recorder := card.NewRecorder(r, workspace)
+			parsedFiles, err := card.ParseFiles(recorder, p, workspace, deckConfig.Parser, filePaths)
+			if err != nil {
+				out <- Result[Deck]{err: err}
+				continue
+			}
+
+			for _, path := range filePaths {
+				// the files are hashed as they will be once their card IDs are written
+				if source, ok := p.Source(filepath.Join(workspace, path)); ok {
+					sources[filepath.Base(path)] = card.HashSource(fingerprint, source)
+				}
+			}
+
+			if links != nil {
+				for _, file := range parsedFiles {
+					links.Add(file.Path, getCardNames(file.Cards))
+				}
+			}
+
+			decks = append(decks, parsedDeck{
+				deckID:    deckID,
+				base:      group.Base,
+				deletion:  deckConfig.Deletion,
+				filePaths: filePaths,
+				sources:   sources,
+				recorder:  recorder,
+				files:     parsedFiles,
+			})
+		}
+
+		for _, parsed := range decks {
+			cards, err := card.Convert(parsed.recorder, c, parsed.files)
 			if err != nil {
 				out <- Result[Deck]{err: err}
 				continue
 			}
 
+			sources := parsed.sources
+			if slices.ContainsFunc(cards, func(card card.Card) bool { return card.Pending }) {
+				logger.Infof("parse(%s): linking to cards not synced yet, parsing again on the next sync", parsed.base)
+				sources = nil
+			} else {
+				maps.Copy(sources, parsed.recorder.Hashes(parsed.filePaths))
+			}
+			lf.Lock()
+			lf.SetFiles(parsed.deckID, sources)
+			lf.Unlock()
+
 			deckHeap := card.Heap(cards)
-			logger.Infof("parse(%s): parsed %d cards into %d decks", group.Base, len(cards), deckHeap.Len())
+			logger.Infof("parse(%s): parsed %d cards into %d decks", parsed.base, len(cards), deckHeap.Len())
+			if deckHeap.Len() == 0 {
+				// the cards of the deck are removed
+				out <- Result[Deck]{data: Deck{deckID: parsed.deckID, deletion: parsed.deletion}}
+			}
 			for deckHeap.Len() > 0 {
 				group := deckHeap.Pop()
 				out <- Result[Deck]{
 					data: Deck{
-						deckID: deckID,
-						name:   group.Base,
-						cards:  group.Items,
+						deckID:   parsed.deckID,
+						name:     group.Base,
+						deletion: parsed.deletion,
+						cards:    group.Items,
 					},
 				}
 			}
@@ -64,6 +167,25 @@ func SyncDecks(ctx context.Context, logger Logger, r parser.Reader, p card.Parse
 	return out
 }
 
+// parsedDeck contains a directory parsed but not converted yet.
+type parsedDeck struct {
+	deckID    string
+	base      string
+	deletion  config.Deletion
+	filePaths []string
+	sources   map[string]string // hashes of the source files
+	recorder  *card.Recorder
+	files     []card.File
+}
+
+func getCardNames(cards []parser.Card) []string {
+	names := make([]string, 0, len(cards))
+	for _, card := range cards {
+		names = append(names, card.Fields["name"])
+	}
+	return names
+}
+
 // Client is the interface the mochi client should implement to generate the sync requests.
 type Client interface {
 	deck.VirtualClient
@@ -74,10 +196,16 @@ type Client interface {
 type Lockfile interface {
 	deck.SyncLockfile
 	deck.VirtualLockfile
+	deck.UnchangedLockfile
+	deck.CleanCardsLockfile
+	ResetFiles(deckID string)
 }
 
 // SyncRequests returns a stream of requests to sync the cards.
-func SyncRequests(ctx context.Context, logger Logger, client Client, lf Lockfile, in <-chan Deck) <-chan Result[request.Request] {
+//
+// Unless full is set, the decks whose cards did not change since
+// the last sync are not listed.
+func SyncRequests(ctx context.Context, logger Logger, client Client, lf Lockfile, full bool, in <-chan Deck) <-chan Result[request.Request] {
 	out := make(chan Result[request.Request], inflightRequests)
 	go func() {
 		defer close(out)
@@ -88,11 +216,18 @@ func SyncRequests(ctx context.Context, logger Logger, client Client, lf Lockfile
 			s.Go(func() stream.Callback {
 				deckID, err := getDeckID(ctx, client, lf, syncDeck)
 				if err != nil {
+					resetFiles(lf, syncDeck.deckID)
 					return func() { out <- Result[request.Request]{err: err} }
 				}
 
-				reqs, err := syncRequests(ctx, logger, client, lf, deckID, syncDeck.cards)
+				if !full && deck.CardsUnchanged(lf, deckID, syncDeck.cards) {
+					logger.Infof("sync(deckID %s): %d cards unchanged, skipping", deckID, len(syncDeck.cards))
+					return func() {}
+				}
+
+				reqs, err := syncRequests(ctx, logger, client, lf, deckID, syncDeck.deletion, syncDeck.cards)
 				if err != nil {
+					resetFiles(lf, syncDeck.deckID)
 					return func() { out <- Result[request.Request]{err: err} }
 				}
 
@@ -117,15 +252,30 @@ func getDeckID(ctx context.Context, client deck.VirtualClient, lf deck.VirtualLo
 	return deck.Virtual(ctx, client, lf, syncDeck.deckID, syncDeck.name)
 }
 
-func syncRequests(ctx context.Context, logger Logger, client Client, lf deck.SyncLockfile, deckID string, cards []card.Card) ([]request.Request, error) {
+func syncRequests(ctx context.Context, logger Logger, client Client, lf Lockfile, deckID string, deletion config.Deletion, cards []card.Card) ([]request.Request, error) {
 	logger.Infof("sync(deckID %s): fetching cards", deckID)
 	mochiCards, err := client.ListCardsInDeck(ctx, deckID)
 	if err != nil {
 		return nil, err
 	}
 	logger.Infof("sync(deckID %s): %d existing cards found", deckID, len(mochiCards))
+	deck.CleanListedCards(lf, deckID, mochiCards)
 
 	logger.Infof("sync(deckID %s): generating sync requests", deckID)
-	reqs := deck.SyncRequests(lf, deckID, mochiCards, cards)
+	reqs := deck.SyncRequests(lf, deckID, deletion, mochiCards, cards)
 	return reqs, nil
 }
+
+// FilesLockfile is the interface the lockfile should implement to reset the source file hashes.
+type FilesLockfile interface {
+	Lock()
+	Unlock()
+	ResetFiles(deckID string)
+}
+
+// resetFiles ensures the directory of a deck is synced again on the next run.
+func resetFiles(lf FilesLockfile, deckID string) {
+	lf.Lock()
+	defer lf.Unlock()
+	lf.ResetFiles(deckID)
+}
@@ -0,0 +1,202 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonhfr/mochi/internal/config"
+	"github.com/leonhfr/mochi/internal/converter"
+	"github.com/leonhfr/mochi/internal/link"
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/internal/parser"
+	"github.com/leonhfr/mochi/internal/test"
+)
+
+func Test_SyncDecks_dependencies(t *testing.T) {
+	files := testFS{
+		"/workspace/a/note.md": "![Cat](cat.png) ![[word.mp3]]\n",
+		"/workspace/a/cat.png": "CAT",
+	}
+	cfg := &config.Config{
+		SkipRoot: true,
+		Decks:    []config.Deck{{Path: "/a", Name: "A"}},
+	}
+	lf := lock.New(files, "/workspace")
+	lf.SetDeck("DECK_A", "", "/a", "A")
+
+	assert.Equal(t, 1, syncDecks(t, files, cfg, lf), "first sync")
+	assert.Equal(t, 0, syncDecks(t, files, cfg, lf), "unchanged")
+
+	files["/workspace/a/cat.png"] = "EDITED CAT"
+	assert.Equal(t, 1, syncDecks(t, files, cfg, lf), "edited image")
+	assert.Equal(t, 0, syncDecks(t, files, cfg, lf), "unchanged")
+
+	files["/workspace/a/word.mp3"] = "WORD"
+	assert.Equal(t, 1, syncDecks(t, files, cfg, lf), "added audio")
+	assert.Equal(t, 0, syncDecks(t, files, cfg, lf), "unchanged")
+
+	cfg.Decks[0].Parser = "headings"
+	assert.Equal(t, 1, syncDecks(t, files, cfg, lf), "changed parser")
+}
+
+func Test_SyncDecks_cardIDs(t *testing.T) {
+	files := testFS{"/workspace/a/note.md": "Note\n"}
+	cfg := &config.Config{
+		SkipRoot: true,
+		CardIDs:  true,
+		Decks:    []config.Deck{{Path: "/a", Name: "A"}},
+	}
+	lf := lock.New(files, "/workspace")
+	lf.SetDeck("DECK_A", "", "/a", "A")
+
+	sync := func() int {
+		p, err := parser.New(parser.WithCardIDs(testWriter(files)))
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		dirC, err := FileWalk(ctx, testLogger{}, files, "/workspace", p.Extensions(), IgnoredFiles(), nil)
+		require.NoError(t, err)
+
+		client := test.NewMockMochi(test.Mochi{})
+		var decks int
+		for result := range SyncDecks(ctx, testLogger{}, files, p, converter.New(), nil, client, cfg, lf, "/workspace", false, dirC) {
+			require.NoError(t, result.err)
+			decks++
+		}
+		require.NoError(t, p.WriteCardIDs(func(string) bool { return false }))
+		return decks
+	}
+
+	assert.Equal(t, 1, sync(), "first sync")
+	assert.Contains(t, files["/workspace/a/note.md"], "mochi-id:")
+	assert.Equal(t, 0, sync(), "unchanged once the card IDs are written")
+}
+
+func Test_SyncDecks_dataFiles(t *testing.T) {
+	files := testFS{
+		"/workspace/a/note.md":      "Note\n",
+		"/workspace/a/words.csv":    "word\nhund\n",
+		"/workspace/b/words.csv":    "word\nhund\nkatze\n",
+		"/workspace/b/package.json": `{"name": "words"}`,
+	}
+	cfg := &config.Config{
+		SkipRoot: true,
+		Decks: []config.Deck{
+			{Path: "/a", Name: "A"},
+			{Path: "/b", Name: "B", DataFiles: true},
+		},
+	}
+	lf := lock.New(files, "/workspace")
+	lf.SetDeck("DECK_A", "", "/a", "A")
+	lf.SetDeck("DECK_B", "", "/b", "B")
+
+	p, err := parser.New(parser.ConfigOptions(cfg, "/workspace")...)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	dirC, err := FileWalk(ctx, testLogger{}, files, "/workspace", p.Extensions(), IgnoredFiles(), nil)
+	require.NoError(t, err)
+
+	client := test.NewMockMochi(test.Mochi{})
+	cards := map[string]int{}
+	for result := range SyncDecks(ctx, testLogger{}, files, p, converter.New(), nil, client, cfg, lf, "/workspace", false, dirC) {
+		require.NoError(t, result.err)
+		cards[result.data.deckID] += len(result.data.cards)
+	}
+	assert.Equal(t, map[string]int{"DECK_A": 1, "DECK_B": 2}, cards)
+}
+
+func Test_SyncDecks_links(t *testing.T) {
+	files := testFS{
+		"/workspace/a/Index.md":  "See [[Target]].\n",
+		"/workspace/b/Target.md": "Target\n",
+	}
+	cfg := &config.Config{
+		SkipRoot: true,
+		Decks:    []config.Deck{{Path: "/a", Name: "A"}, {Path: "/b", Name: "B"}},
+	}
+	lf := lock.New(files, "/workspace")
+	lf.SetDeck("DECK_A", "", "/a", "A")
+	lf.SetDeck("DECK_B", "", "/b", "B")
+
+	// the target is added in the same sync
+	contents := syncLinks(t, files, cfg, lf)
+	assert.Equal(t, map[string]string{"DECK_A": "# Index\n\nSee Target.\n", "DECK_B": "# Target\n\nTarget\n"}, contents)
+	deckA, _ := lf.Deck("DECK_A")
+	assert.Empty(t, deckA.Files)
+
+	// the target is synced, the linking deck is parsed again
+	require.NoError(t, lf.SetCard("DECK_B", "CARD_TARGET", lock.Card{Filename: "Target.md", Name: "Target"}))
+	contents = syncLinks(t, files, cfg, lf)
+	assert.Equal(t, map[string]string{"DECK_A": "# Index\n\nSee [[CARD_TARGET]].\n"}, contents)
+	deckA, _ = lf.Deck("DECK_A")
+	assert.NotEmpty(t, deckA.Files)
+}
+
+// syncLinks returns the content of the cards output by SyncDecks
+// with the links resolved, indexed by deck id.
+func syncLinks(t *testing.T, files testFS, cfg *config.Config, lf *lock.Lock) map[string]string {
+	t.Helper()
+
+	p, err := parser.New()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	dirC, err := FileWalk(ctx, testLogger{}, files, "/workspace", p.Extensions(), IgnoredFiles(), nil)
+	require.NoError(t, err)
+
+	links := link.New(lf, "/workspace", testLogger{})
+	c := converter.New(converter.WithLinks(links))
+	client := test.NewMockMochi(test.Mochi{})
+	contents := map[string]string{}
+	for result := range SyncDecks(ctx, testLogger{}, files, p, c, links, client, cfg, lf, "/workspace", false, dirC) {
+		require.NoError(t, result.err)
+		for _, card := range result.data.cards {
+			contents[result.data.deckID] += card.Content
+		}
+	}
+	return contents
+}
+
+// syncDecks returns the number of decks output by SyncDecks.
+func syncDecks(t *testing.T, files testFS, cfg *config.Config, lf *lock.Lock) int {
+	t.Helper()
+
+	p, err := parser.New()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	dirC, err := FileWalk(ctx, testLogger{}, files, "/workspace", p.Extensions(), IgnoredFiles(), nil)
+	require.NoError(t, err)
+
+	client := test.NewMockMochi(test.Mochi{})
+	var decks int
+	for result := range SyncDecks(ctx, testLogger{}, files, p, converter.New(), nil, client, cfg, lf, "/workspace", false, dirC) {
+		require.NoError(t, result.err)
+		decks++
+	}
+	return decks
+}
+
+// testWriter writes the files to the test file system on close.
+type testWriter testFS
+
+func (w testWriter) Write(path string) (io.WriteCloser, error) {
+	return &testFile{files: testFS(w), path: path}, nil
+}
+
+type testFile struct {
+	strings.Builder
+	files testFS
+	path  string
+}
+
+func (f *testFile) Close() error {
+	f.files[f.path] = f.String()
+	return nil
+}
@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"errors"
+
+	"github.com/leonhfr/mochi/internal/config"
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/internal/request"
+)
+
+// ThresholdLockfile is the interface the lockfile should implement to check the deletion threshold.
+type ThresholdLockfile interface {
+	Lock()
+	Unlock()
+	Decks() map[string]lock.Deck
+	ResetFiles(deckID string)
+}
+
+// GuardRequests buffers the requests and checks them against the deletion threshold
+// before letting any of them through.
+//
+// When forced, exceeding the threshold is only logged. Otherwise the
+// directories of the requests are synced again on the next run.
+func GuardRequests(logger Logger, lf ThresholdLockfile, threshold config.Threshold, force bool, in <-chan request.Request) (<-chan request.Request, error) {
+	var reqs []request.Request
+	for req := range in {
+		reqs = append(reqs, req)
+	}
+
+	err := CheckThreshold(lf, threshold, reqs)
+	if errors.Is(err, request.ErrThresholdExceeded) && force {
+		logger.Infof("threshold: %v, forced", err)
+	} else if err != nil {
+		resetRequestFiles(lf, reqs)
+		return nil, err
+	}
+
+	out := make(chan request.Request, len(reqs))
+	for _, req := range reqs {
+		out <- req
+	}
+	close(out)
+
+	return out, nil
+}
+
+// CheckThreshold checks the requests against the deletion threshold.
+func CheckThreshold(lf ThresholdLockfile, threshold config.Threshold, reqs []request.Request) error {
+	lf.Lock()
+	tracked := 0
+	for _, deck := range lf.Decks() {
+		tracked += len(deck.Cards)
+	}
+	lf.Unlock()
+
+	return request.CheckThreshold(reqs, tracked, threshold.Count, threshold.Percent)
+}
+
+func resetRequestFiles(lf ThresholdLockfile, reqs []request.Request) {
+	lf.Lock()
+	defer lf.Unlock()
+	for _, req := range reqs {
+		summary := req.Summary()
+		lf.ResetFiles(summary.DeckID)
+		if summary.PreviousDeckID != "" {
+			lf.ResetFiles(summary.PreviousDeckID)
+		}
+	}
+}
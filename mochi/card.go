@@ -3,6 +3,7 @@ package mochi
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -40,14 +41,43 @@ type CreateCardRequest struct {
 }
 
 // UpdateCardRequest holds the info to update a card.
+//
+// The zero values are omitted, so a false Archived or ReviewReverse
+// leaves the card untouched. Unarchive and DisableReviewReverse
+// explicitly reset them.
 type UpdateCardRequest struct {
-	Content       string           `json:"content,omitempty"`
-	DeckID        string           `json:"deck-id,omitempty"`
-	TemplateID    string           `json:"template-id,omitempty"`
-	Archived      bool             `json:"archived?,omitempty"`
-	ReviewReverse bool             `json:"review-reverse?,omitempty"`
-	Pos           string           `json:"pos,omitempty"`
-	Fields        map[string]Field `json:"fields,omitempty"`
+	Content              string           `json:"content,omitempty"`
+	DeckID               string           `json:"deck-id,omitempty"`
+	TemplateID           string           `json:"template-id,omitempty"`
+	Archived             bool             `json:"archived?,omitempty"`
+	ReviewReverse        bool             `json:"review-reverse?,omitempty"`
+	Pos                  string           `json:"pos,omitempty"`
+	Fields               map[string]Field `json:"fields,omitempty"`
+	Unarchive            bool             `json:"-"` // sends archived? false
+	DisableReviewReverse bool             `json:"-"` // sends review-reverse? false
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r UpdateCardRequest) MarshalJSON() ([]byte, error) {
+	type alias UpdateCardRequest
+	return json.Marshal(struct {
+		alias
+		Archived      *bool `json:"archived?,omitempty"`
+		ReviewReverse *bool `json:"review-reverse?,omitempty"`
+	}{
+		alias:         alias(r),
+		Archived:      explicitBool(r.Archived, r.Unarchive),
+		ReviewReverse: explicitBool(r.ReviewReverse, r.DisableReviewReverse),
+	})
+}
+
+// explicitBool returns the value to send for a boolean that is
+// omitted when false, unless reset is set.
+func explicitBool(value, reset bool) *bool {
+	if !value && !reset {
+		return nil
+	}
+	return &value
 }
 
 // Field represents a field.
@@ -120,7 +150,24 @@ func (c *Client) AddAttachment(ctx context.Context, cardID, filename string, dat
 		Pathf("%s/%s/attachments/%s", cardPath, cardID, filename).
 		Method(http.MethodPost).
 		Header("Content-Type", writer.FormDataContentType()).
-		BodyReader(body)
+		BodyBytes(body.Bytes())
 	err = executeRequest(ctx, rb)
 	return err
 }
+
+// DeleteAttachment deletes an attachment of a card.
+func (c *Client) DeleteAttachment(ctx context.Context, cardID, filename string) error {
+	return deleteItem(ctx, c, cardPath+"/"+cardID+"/attachments", filename)
+}
+
+// GetAttachment downloads an attachment of a card.
+func (c *Client) GetAttachment(ctx context.Context, cardID, filename string) ([]byte, error) {
+	var buf bytes.Buffer
+	rb := buildRequest(c).
+		Pathf("%s/%s/attachments/%s", cardPath, cardID, filename).
+		Method(http.MethodGet).
+		Accept("*/*").
+		ToBytesBuffer(&buf)
+	err := executeRequest(ctx, rb)
+	return buf.Bytes(), err
+}
@@ -2,8 +2,18 @@ package mochi
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leonhfr/mochi/internal/retry"
 )
 
 func Test_CreateCard(t *testing.T) {
@@ -254,6 +264,38 @@ func Test_UpdateCard(t *testing.T) {
 	}
 }
 
+func Test_UpdateCardRequest_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		req  UpdateCardRequest
+		want string
+	}{
+		{
+			name: "omits the false booleans",
+			req:  UpdateCardRequest{Content: "Card content"},
+			want: `{"content":"Card content"}`,
+		},
+		{
+			name: "sends the true booleans",
+			req:  UpdateCardRequest{Archived: true, ReviewReverse: true},
+			want: `{"archived?":true,"review-reverse?":true}`,
+		},
+		{
+			name: "resets the booleans",
+			req:  UpdateCardRequest{Unarchive: true, DisableReviewReverse: true},
+			want: `{"archived?":false,"review-reverse?":false}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.req)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
 func Test_DeleteCard(t *testing.T) {
 	tests := []struct {
 		name string
@@ -285,3 +327,110 @@ func Test_DeleteCard(t *testing.T) {
 		}))
 	}
 }
+
+func Test_AddAttachment_retry(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/cards/CARD_ID/attachments/image.png", r.URL.Path)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New("TOKEN", WithTransport(retry.New(
+		retry.WithIdempotent(Idempotent),
+		retry.WithBackoff(time.Millisecond, time.Millisecond),
+	)))
+	client.baseURL = server.URL
+
+	err := client.AddAttachment(context.Background(), "CARD_ID", "image.png", []byte("IMAGE"))
+
+	require.NoError(t, err)
+	require.Len(t, bodies, 2)
+	assert.Contains(t, bodies[1], "IMAGE")
+	assert.Equal(t, bodies[0], bodies[1])
+}
+
+func Test_DeleteAttachment(t *testing.T) {
+	tests := []struct {
+		name string
+		test deleteItemTestCase
+	}{
+		{
+			name: "should delete an attachment",
+			test: deleteItemTestCase{
+				status: http.StatusOK,
+				id:     "image.png",
+				res:    nil,
+				err:    "",
+			},
+		},
+		{
+			name: "should return an error",
+			test: deleteItemTestCase{
+				status: http.StatusBadRequest,
+				id:     "image.png",
+				res:    `{"errors":["ERROR_MESSAGE"]}`,
+				err:    "mochi: ERROR_MESSAGE",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, testDeleteItem("/api/cards/CARD_ID/attachments", tt.test, func(client *Client, filename string) error {
+			return client.DeleteAttachment(context.Background(), "CARD_ID", filename)
+		}))
+	}
+}
+
+func Test_GetAttachment(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		res    string
+		want   []byte
+		err    string
+	}{
+		{
+			name:   "should download an attachment",
+			status: http.StatusOK,
+			res:    "IMAGE",
+			want:   []byte("IMAGE"),
+		},
+		{
+			name:   "should return an error",
+			status: http.StatusNotFound,
+			res:    `{"errors":["ERROR_MESSAGE"]}`,
+			err:    "mochi: ERROR_MESSAGE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer gock.Off()
+
+			token := "TOKEN"
+			gock.New(baseURL).
+				BasicAuth(token, "").
+				Get("/api/cards/CARD_ID/attachments/image.png").
+				Reply(tt.status).
+				BodyString(tt.res)
+
+			got, err := New(token).GetAttachment(context.Background(), "CARD_ID", "image.png")
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+			require.True(t, gock.IsDone())
+		})
+	}
+}
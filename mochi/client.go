@@ -1,6 +1,9 @@
 package mochi
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 const baseURL = "https://app.mochi.cards/"
 
@@ -42,3 +45,19 @@ func WithTransport(transport http.RoundTripper) Option {
 		c.transport = transport
 	}
 }
+
+// Idempotent reports whether a request to the mochi API can safely be repeated.
+//
+// Besides the idempotent methods, the POST requests that target an existing
+// item are idempotent: updates and attachment uploads. Creations are not.
+func Idempotent(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		return len(segments) > 2 // api/cards/:id
+	default:
+		return false
+	}
+}
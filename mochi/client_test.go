@@ -0,0 +1,32 @@
+package mochi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Idempotent(t *testing.T) {
+	tests := []struct {
+		method string
+		target string
+		want   bool
+	}{
+		{method: http.MethodGet, target: "/api/cards/", want: true},
+		{method: http.MethodDelete, target: "/api/cards/CARD_ID", want: true},
+		{method: http.MethodPost, target: "/api/cards/", want: false},
+		{method: http.MethodPost, target: "/api/decks", want: false},
+		{method: http.MethodPost, target: "/api/cards/CARD_ID", want: true},
+		{method: http.MethodPost, target: "/api/cards/CARD_ID/attachments/image.png", want: true},
+		{method: http.MethodPatch, target: "/api/cards/CARD_ID", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			assert.Equal(t, tt.want, Idempotent(r))
+		})
+	}
+}
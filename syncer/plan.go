@@ -0,0 +1,114 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/internal/request"
+	"github.com/leonhfr/mochi/internal/worker"
+	"github.com/leonhfr/mochi/mochi"
+)
+
+// plan logs the requests a sync would execute, grouped by deck,
+// and records them in the result.
+func (s *Syncer) plan(res *Result, lf *lock.Lock, threshold Threshold, in <-chan request.Request) {
+	var reqs []request.Request
+	for req := range in {
+		reqs = append(reqs, req)
+		res.Record(req.Summary(), 0, nil)
+	}
+
+	logPlan(s.logger, lf, reqs)
+	if err := worker.CheckThreshold(lf, threshold, reqs); err != nil {
+		s.logger.Errorf("plan: %v", err)
+	}
+}
+
+// planLockfile is the interface the lockfile should implement to print the plan.
+type planLockfile interface {
+	Lock()
+	Unlock()
+	Deck(id string) (lock.Deck, bool)
+}
+
+func logPlan(logger Logger, lf planLockfile, reqs []request.Request) {
+	grouped := make(map[string][]string)
+	for _, req := range reqs {
+		deckID := req.Summary().DeckID
+		grouped[deckID] = append(grouped[deckID], req.String())
+	}
+
+	lf.Lock()
+	defer lf.Unlock()
+
+	headers := make(map[string]string, len(grouped))
+	deckIDs := make([]string, 0, len(grouped))
+	for deckID := range grouped {
+		headers[deckID] = planDeckHeader(lf, deckID)
+		deckIDs = append(deckIDs, deckID)
+	}
+	slices.SortFunc(deckIDs, func(a, b string) int {
+		return strings.Compare(headers[a], headers[b])
+	})
+
+	logger.Infof("plan: %d requests in %d decks", len(reqs), len(grouped))
+	for _, deckID := range deckIDs {
+		lines := grouped[deckID]
+		slices.Sort(lines)
+		logger.Infof("plan: %s: %d requests", headers[deckID], len(lines))
+		for _, line := range lines {
+			logger.Infof("  %s", line)
+		}
+	}
+}
+
+func planDeckHeader(lf planLockfile, deckID string) string {
+	deck, ok := lf.Deck(deckID)
+	switch {
+	case !ok:
+		return fmt.Sprintf("deck %s", deckID)
+	case deck.Virtual:
+		return fmt.Sprintf("deck %s (virtual, %s)", deck.Name, deckID)
+	default:
+		return fmt.Sprintf("deck %s (%s, %s)", deck.Name, deck.Path, deckID)
+	}
+}
+
+const plannedDeckPrefix = "planned-deck-"
+
+// planClient wraps a mochi client and prevents any deck mutation.
+//
+// Created decks are given a placeholder ID and are considered empty.
+type planClient struct {
+	client *mochi.Client
+	count  atomic.Int64
+}
+
+func newPlanClient(client *mochi.Client) *planClient {
+	return &planClient{client: client}
+}
+
+// CreateDeck returns a planned deck without creating it.
+func (c *planClient) CreateDeck(_ context.Context, req mochi.CreateDeckRequest) (mochi.Deck, error) {
+	id := fmt.Sprintf("%s%d", plannedDeckPrefix, c.count.Add(1))
+	return mochi.Deck{ID: id, Name: req.Name, ParentID: req.ParentID}, nil
+}
+
+// UpdateDeck returns the updated deck without updating it.
+func (c *planClient) UpdateDeck(_ context.Context, id string, req mochi.UpdateDeckRequest) (mochi.Deck, error) {
+	return mochi.Deck{ID: id, Name: req.Name, ParentID: req.ParentID}, nil
+}
+
+// ListCardsInDeck lists the cards in a deck.
+//
+// Planned decks do not contain any card.
+func (c *planClient) ListCardsInDeck(ctx context.Context, id string) ([]mochi.Card, error) {
+	if strings.HasPrefix(id, plannedDeckPrefix) {
+		return nil, nil
+	}
+	return c.client.ListCardsInDeck(ctx, id)
+}
@@ -0,0 +1,381 @@
+// Package syncer syncs a workspace of notes to mochi decks and cards.
+//
+// It exposes the sync engine used by the mochi CLI and GitHub Action
+// so that it can be embedded in other programs.
+package syncer
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/yuin/goldmark"
+
+	"github.com/leonhfr/mochi/internal/config"
+	"github.com/leonhfr/mochi/internal/converter"
+	"github.com/leonhfr/mochi/internal/deck"
+	"github.com/leonhfr/mochi/internal/file"
+	"github.com/leonhfr/mochi/internal/link"
+	"github.com/leonhfr/mochi/internal/parser"
+	"github.com/leonhfr/mochi/internal/report"
+	"github.com/leonhfr/mochi/internal/request"
+	"github.com/leonhfr/mochi/internal/vault"
+	"github.com/leonhfr/mochi/internal/worker"
+	"github.com/leonhfr/mochi/mochi"
+)
+
+// FS is the interface to the filesystem the workspace lives in.
+//
+// The paths are absolute. Walk calls the callback with the paths
+// relative to the workspace of the files matching the extensions.
+type FS interface {
+	Walk(workspace string, extensions []string, cb func(path string)) error
+	Read(path string) (io.ReadCloser, error)
+	Write(path string) (io.WriteCloser, error)
+}
+
+// NewFS returns the operating system filesystem.
+func NewFS() FS { return file.NewSystem() }
+
+// Logger is the interface to log output.
+type Logger interface {
+	Debugf(format string, args ...any)
+	Errorf(format string, args ...any)
+	Infof(format string, args ...any)
+}
+
+// Config represents the config of a workspace.
+type Config = config.Config
+
+// Deck represents the config of a deck.
+type Deck = config.Deck
+
+// Deletion represents the policy applied to cards whose source vanished.
+type Deletion = config.Deletion
+
+// Deletion policies.
+const (
+	DeletionDelete  = config.DeletionDelete
+	DeletionArchive = config.DeletionArchive
+	DeletionKeep    = config.DeletionKeep
+)
+
+// Threshold represents the maximum number of cards a sync may delete or archive.
+type Threshold = config.Threshold
+
+// VocabularyTemplate represents a vocabulary template.
+type VocabularyTemplate = config.VocabularyTemplate
+
+// TableTemplate represents a table template.
+type TableTemplate = config.TableTemplate
+
+// GlossaryTemplate represents a glossary template.
+type GlossaryTemplate = config.GlossaryTemplate
+
+// PatternParser represents a parser declared in the config.
+type PatternParser = config.PatternParser
+
+// PluginParser represents a parser running an external executable.
+type PluginParser = config.PluginParser
+
+// LoadConfig parses the config in the workspace.
+//
+// The names of the custom parsers are accepted as deck parsers.
+func LoadConfig(fs FS, workspace string, parsers ...string) (*Config, error) {
+	return config.Parse(fs, workspace, append(parser.Names(), parsers...))
+}
+
+// Card represents a card returned by a parser.
+type Card = parser.Card
+
+// ParseResult represents the cards parsed from a file.
+type ParseResult = parser.Result
+
+// Parser is the interface that should be implemented by custom parsers.
+type Parser interface {
+	Parse(path string, source []byte) (ParseResult, error)
+}
+
+// Result represents the outcome of a sync.
+type Result = report.Report
+
+// RequestResult represents the outcome of an executed request.
+type RequestResult = report.Request
+
+// Kind represents the kind of a request.
+type Kind = request.Kind
+
+// Request kinds.
+const (
+	KindCreate  = request.KindCreate
+	KindUpdate  = request.KindUpdate
+	KindArchive = request.KindArchive
+	KindDelete  = request.KindDelete
+)
+
+// Events holds the callbacks called during a sync.
+//
+// The callbacks may be called concurrently.
+type Events struct {
+	OnRequest func(RequestResult) // called after each executed request
+	OnError   func(error)         // called for each error that does not abort the sync
+}
+
+// Syncer syncs workspaces.
+type Syncer struct {
+	fs         FS
+	client     *mochi.Client
+	config     *Config
+	logger     Logger
+	events     Events
+	parsers    []parser.Option
+	names      []string
+	extensions []goldmark.Extender
+	force      bool
+	full       bool
+	dryRun     bool
+}
+
+// Option represents an option for the syncer.
+type Option func(*Syncer)
+
+// WithConfig sets the config.
+//
+// It is validated and its default values filled in like a parsed config
+// when syncing. By default, the config is parsed from the workspace.
+func WithConfig(config *Config) Option {
+	return func(s *Syncer) {
+		s.config = config
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(logger Logger) Option {
+	return func(s *Syncer) {
+		s.logger = logger
+	}
+}
+
+// WithEvents sets the event callbacks.
+func WithEvents(events Events) Option {
+	return func(s *Syncer) {
+		s.events = events
+	}
+}
+
+// WithParser adds a custom parser that decks may use by name.
+//
+// It is the default parser of the additional extensions, e.g. ".pgn".
+func WithParser(name string, p Parser, extensions ...string) Option {
+	return func(s *Syncer) {
+		s.parsers = append(s.parsers, parser.WithParser(name, p, extensions...))
+		s.names = append(s.names, name)
+	}
+}
+
+// WithExtensions adds goldmark extensions to the markdown converter.
+func WithExtensions(extensions ...goldmark.Extender) Option {
+	return func(s *Syncer) {
+		s.extensions = append(s.extensions, extensions...)
+	}
+}
+
+// WithForce executes the requests even above the deletion threshold.
+func WithForce(force bool) Option {
+	return func(s *Syncer) {
+		s.force = force
+	}
+}
+
+// WithFull syncs every directory, even the unchanged ones.
+func WithFull(full bool) Option {
+	return func(s *Syncer) {
+		s.full = full
+	}
+}
+
+// WithDryRun plans the sync without executing it.
+//
+// The decks are not created, the requests are not executed and the
+// lockfile is not written. The planned requests are logged grouped by
+// deck and recorded in the result, without calling the request callback.
+// Exceeding the deletion threshold is only logged.
+func WithDryRun(dryRun bool) Option {
+	return func(s *Syncer) {
+		s.dryRun = dryRun
+	}
+}
+
+// New returns a new Syncer.
+func New(fs FS, client *mochi.Client, options ...Option) *Syncer {
+	s := &Syncer{
+		fs:     fs,
+		client: client,
+		logger: noOpLogger{},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Sync syncs the workspace.
+//
+// The returned result is never nil and records the requests
+// executed before any error occurred. No request is executed
+// when the deletion threshold is exceeded, unless forced.
+func (s *Syncer) Sync(ctx context.Context, workspace string) (res *Result, err error) {
+	res = report.New()
+
+	config, err := s.loadConfig(workspace)
+	if err != nil {
+		return res, err
+	}
+
+	parser, err := s.parser(config, workspace)
+	if err != nil {
+		return res, err
+	}
+
+	lf, err := worker.LoadLockfile(ctx, s.logger, s.client, s.fs, workspace, s.full)
+	if err != nil {
+		return res, err
+	}
+
+	vault, err := vault.New(s.fs, workspace, converter.EmbedExtensions())
+	if err != nil {
+		return res, err
+	}
+
+	links := link.New(lf, workspace, s.logger)
+	converter := converter.New(
+		converter.WithExtensions(s.extensions...),
+		converter.WithLinks(links),
+		converter.WithVault(vault),
+		converter.WithLogger(s.logger),
+	)
+
+	if !s.dryRun {
+		defer func() {
+			res.LockfileUpdated = lf.Updated()
+			if writeErr := lf.Write(); err == nil {
+				err = writeErr
+			}
+		}()
+	}
+
+	wg := &sync.WaitGroup{}
+	errC := make(chan error)
+	errDone := make(chan struct{})
+	defer func() {
+		// the error callbacks are all called before returning
+		close(errC)
+		<-errDone
+	}()
+
+	go func() {
+		defer close(errDone)
+		for err := range errC {
+			s.logger.Errorf("workers: %v", err)
+			if s.events.OnError != nil {
+				s.events.OnError(err)
+			}
+		}
+	}()
+
+	dirC, err := worker.FileWalk(ctx, s.logger, s.fs, workspace, parser.Extensions(), worker.IgnoredFiles(), worker.LockfileDirs(lf))
+	if err != nil {
+		return res, err
+	}
+
+	var client syncClient = s.client
+	if s.dryRun {
+		client = newPlanClient(s.client)
+	}
+
+	deckR := worker.SyncDecks(ctx, s.logger, s.fs, parser, converter, links, client, config, lf, workspace, s.full, dirC)
+	deckC := worker.Unwrap(wg, deckR, errC)
+	syncR := worker.SyncRequests(ctx, s.logger, client, lf, s.full, deckC)
+	syncC := worker.Unwrap(wg, syncR, errC)
+	moveR := worker.MoveRequests(ctx, s.logger, syncC)
+	moveC := worker.Unwrap(wg, moveR, errC)
+
+	if s.dryRun {
+		s.plan(res, lf, config.Threshold, moveC)
+		wg.Wait()
+		return res, nil
+	}
+	guardC, err := worker.GuardRequests(s.logger, lf, config.Threshold, s.force, moveC)
+	if err != nil {
+		wg.Wait()
+		return res, err
+	}
+	rec := &recorder{report: res, events: s.events, failed: map[string]bool{}}
+	doneR := worker.ExecuteRequests(ctx, s.logger, s.client, lf, rec, guardC)
+	_ = worker.Unwrap(wg, doneR, errC)
+
+	wg.Wait()
+
+	// the card IDs are written once the cards exist in mochi
+	return res, parser.WriteCardIDs(rec.hasFailed)
+}
+
+// syncClient is the interface the client should implement to sync the decks.
+type syncClient interface {
+	deck.CreateClient
+	worker.Client
+}
+
+// loadConfig returns the config set by WithConfig once validated,
+// or the config parsed from the workspace.
+func (s *Syncer) loadConfig(workspace string) (*Config, error) {
+	if s.config == nil {
+		return LoadConfig(s.fs, workspace, s.names...)
+	}
+	return config.Validate(*s.config, append(parser.Names(), s.names...))
+}
+
+func (s *Syncer) parser(config *Config, workspace string) (*parser.Parser, error) {
+	options := append(parser.ConfigOptions(config, workspace), s.parsers...)
+	// a dry run leaves the sources untouched
+	if config.CardIDs && !s.dryRun {
+		options = append(options, parser.WithCardIDs(s.fs))
+	}
+	return parser.New(options...)
+}
+
+// recorder records the executed requests in the report
+// and calls the request callback.
+type recorder struct {
+	report *report.Report
+	events Events
+	mu     sync.Mutex
+	failed map[string]bool // map[path]bool of the source files with failed requests
+}
+
+// Record implements the worker.Recorder interface.
+func (r *recorder) Record(summary request.Summary, duration time.Duration, err error) {
+	r.report.Record(summary, duration, err)
+	if r.events.OnRequest != nil {
+		r.events.OnRequest(report.NewRequest(summary, duration, err))
+	}
+	if err != nil && summary.Path != "" {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.failed[summary.Path] = true
+	}
+}
+
+// hasFailed returns whether a request of the source file failed.
+func (r *recorder) hasFailed(path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failed[path]
+}
+
+type noOpLogger struct{}
+
+func (noOpLogger) Debugf(string, ...any) {}
+func (noOpLogger) Errorf(string, ...any) {}
+func (noOpLogger) Infof(string, ...any)  {}
@@ -0,0 +1,415 @@
+package syncer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"github.com/leonhfr/mochi/internal/lock"
+	"github.com/leonhfr/mochi/mochi"
+)
+
+const workspace = "/workspace"
+
+func Test_Syncer_Sync(t *testing.T) {
+	files := newTestFS(map[string]string{
+		"/workspace/notes/Cooking.md": "Recipes.\n",
+	})
+	server := newTestServer(t)
+
+	var events []RequestResult
+	s := New(files, server.client(),
+		WithConfig(&Config{SkipRoot: true, Decks: []Deck{{Path: "notes", Name: "Notes"}}}),
+		WithEvents(Events{OnRequest: func(req RequestResult) { events = append(events, req) }}),
+	)
+	res, err := s.Sync(context.Background(), workspace)
+	require.NoError(t, err)
+
+	require.Len(t, res.Requests, 1)
+	assert.Equal(t, KindCreate, res.Requests[0].Kind)
+	assert.Equal(t, "Cooking.md", res.Requests[0].Filename)
+	assert.Equal(t, res.Requests, events)
+	assert.True(t, res.LockfileUpdated)
+
+	assert.Equal(t, []mochi.Deck{{ID: "deck-1", Name: "Notes"}}, server.decks)
+	require.Len(t, server.cards, 1)
+	assert.Equal(t, "# Cooking\n\nRecipes.\n", server.cards[0].Content)
+	assert.Contains(t, files.files, filepath.Join(workspace, lock.Filename))
+
+	// the unchanged directory is skipped
+	res, err = New(files, server.client(), WithConfig(&Config{SkipRoot: true, Decks: []Deck{{Path: "notes", Name: "Notes"}}})).
+		Sync(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.Empty(t, res.Requests)
+	assert.Len(t, server.cards, 1)
+}
+
+func Test_Syncer_Sync_config(t *testing.T) {
+	files := newTestFS(map[string]string{
+		"/workspace/mochi.yaml":       "skipRoot: true\ndecks:\n  - path: notes\n    name: Notes\n",
+		"/workspace/notes/Cooking.md": "Recipes.\n",
+	})
+	server := newTestServer(t)
+
+	res, err := New(files, server.client()).Sync(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.Len(t, res.Requests, 1)
+
+	config := &Config{Decks: []Deck{{Path: "notes", Parser: "unknown"}}}
+	_, err = New(files, server.client(), WithConfig(config)).Sync(context.Background(), workspace)
+	assert.Error(t, err, "the config set by WithConfig is validated")
+	assert.Equal(t, "notes", config.Decks[0].Path, "the config set by WithConfig is left untouched")
+}
+
+func Test_Syncer_Sync_dryRun(t *testing.T) {
+	files := newTestFS(map[string]string{
+		"/workspace/notes/Cooking.md": "Recipes.\n",
+	})
+	server := newTestServer(t)
+
+	logger := &testLogger{}
+	var events []RequestResult
+	s := New(files, server.client(),
+		WithConfig(&Config{SkipRoot: true, Decks: []Deck{{Path: "/notes", Name: "Notes"}}}),
+		WithLogger(logger),
+		WithEvents(Events{OnRequest: func(req RequestResult) { events = append(events, req) }}),
+		WithDryRun(true),
+	)
+	res, err := s.Sync(context.Background(), workspace)
+	require.NoError(t, err)
+
+	require.Len(t, res.Requests, 1)
+	assert.Equal(t, KindCreate, res.Requests[0].Kind)
+	assert.Empty(t, events)
+	assert.False(t, res.LockfileUpdated)
+	assert.Contains(t, logger.lines, "plan: 1 requests in 1 decks")
+
+	assert.Empty(t, server.decks)
+	assert.Empty(t, server.cards)
+	assert.NotContains(t, files.files, filepath.Join(workspace, lock.Filename))
+}
+
+func Test_Syncer_Sync_dryRun_cardIDs(t *testing.T) {
+	source := "Recipes.\n"
+	files := newTestFS(map[string]string{
+		"/workspace/notes/Cooking.md": source,
+	})
+	server := newTestServer(t)
+
+	s := New(files, server.client(),
+		WithConfig(&Config{SkipRoot: true, CardIDs: true, Decks: []Deck{{Path: "/notes", Name: "Notes"}}}),
+		WithDryRun(true),
+	)
+	res, err := s.Sync(context.Background(), workspace)
+	require.NoError(t, err)
+
+	require.Len(t, res.Requests, 1)
+	assert.Equal(t, map[string]string{"/workspace/notes/Cooking.md": source}, files.files)
+}
+
+func Test_Syncer_Sync_cardIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		failCards bool
+		want      *regexp.Regexp
+	}{
+		{
+			name: "written after creation",
+			want: regexp.MustCompile(`^<!-- mochi-id: [0-9a-f]{12} -->\nRecipes\.\n$`),
+		},
+		{
+			name:      "not written when the creation fails",
+			failCards: true,
+			want:      regexp.MustCompile(`^Recipes\.\n$`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := newTestFS(map[string]string{
+				"/workspace/notes/Cooking.md": "Recipes.\n",
+			})
+			server := newTestServer(t)
+			server.failCards = tt.failCards
+
+			s := New(files, server.client(),
+				WithConfig(&Config{SkipRoot: true, CardIDs: true, Decks: []Deck{{Path: "/notes", Name: "Notes"}}}),
+			)
+			_, err := s.Sync(context.Background(), workspace)
+			require.NoError(t, err)
+
+			assert.Regexp(t, tt.want, files.files["/workspace/notes/Cooking.md"])
+		})
+	}
+}
+
+func Test_Syncer_Sync_errors(t *testing.T) {
+	files := newTestFS(map[string]string{
+		"/workspace/notes/Cooking.md": "Recipes.\n",
+	})
+	server := newTestServer(t)
+	server.failCards = true
+
+	var mu sync.Mutex
+	var events []RequestResult
+	var errs []error
+	s := New(files, server.client(),
+		WithConfig(&Config{SkipRoot: true, Decks: []Deck{{Path: "/notes", Name: "Notes"}}}),
+		WithEvents(Events{
+			OnRequest: func(req RequestResult) { events = append(events, req) },
+			OnError: func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, err)
+			},
+		}),
+	)
+	res, err := s.Sync(context.Background(), workspace)
+	require.NoError(t, err)
+
+	require.Len(t, res.Requests, 1)
+	assert.NotEmpty(t, res.Requests[0].Error)
+	assert.Equal(t, res.Requests, events)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, errs, 1)
+}
+
+func Test_Syncer_Sync_parser(t *testing.T) {
+	files := newTestFS(map[string]string{
+		"/workspace/chess/Openings.pgn": "1. e4 e5\n1. d4 d5\n",
+	})
+	server := newTestServer(t)
+
+	s := New(files, server.client(),
+		WithConfig(&Config{SkipRoot: true, Decks: []Deck{{Path: "/chess", Name: "Chess", Parser: "pgn"}}}),
+		WithParser("pgn", testParser{}, ".pgn"),
+	)
+	res, err := s.Sync(context.Background(), workspace)
+	require.NoError(t, err)
+
+	assert.Len(t, res.Requests, 2)
+	contents := make([]string, 0, len(server.cards))
+	for _, card := range server.cards {
+		contents = append(contents, card.Content)
+	}
+	slices.Sort(contents)
+	assert.Equal(t, []string{"1. d4 d5\n", "1. e4 e5\n"}, contents)
+}
+
+func Test_Syncer_Sync_extensions(t *testing.T) {
+	files := newTestFS(map[string]string{
+		"/workspace/notes/Cooking.md": "Recipes.\n",
+	})
+	server := newTestServer(t)
+
+	s := New(files, server.client(),
+		WithConfig(&Config{SkipRoot: true, Decks: []Deck{{Path: "/notes", Name: "Notes"}}}),
+		WithExtensions(testExtension{}),
+	)
+	_, err := s.Sync(context.Background(), workspace)
+	require.NoError(t, err)
+
+	require.Len(t, server.cards, 1)
+	assert.Equal(t, "Recipes.\n", server.cards[0].Content)
+}
+
+// testFS represents an in-memory filesystem.
+type testFS struct {
+	mu    sync.Mutex
+	files map[string]string
+}
+
+func newTestFS(files map[string]string) *testFS {
+	return &testFS{files: files}
+}
+
+// Walk implements the FS interface.
+func (f *testFS) Walk(workspace string, extensions []string, cb func(string)) error {
+	f.mu.Lock()
+	paths := make([]string, 0, len(f.files))
+	for path := range f.files {
+		if slices.Contains(extensions, filepath.Ext(path)) {
+			paths = append(paths, strings.TrimPrefix(path, workspace))
+		}
+	}
+	f.mu.Unlock()
+
+	slices.Sort(paths)
+	for _, path := range paths {
+		cb(path)
+	}
+	return nil
+}
+
+// Read implements the FS interface.
+func (f *testFS) Read(path string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	content, ok := f.files[path]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+// Write implements the FS interface.
+func (f *testFS) Write(path string) (io.WriteCloser, error) {
+	return &testFile{fs: f, path: path}, nil
+}
+
+type testFile struct {
+	bytes.Buffer
+	fs   *testFS
+	path string
+}
+
+func (f *testFile) Close() error {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	f.fs.files[f.path] = f.String()
+	return nil
+}
+
+// testServer represents an in-memory mochi API.
+type testServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	decks     []mochi.Deck
+	cards     []mochi.Card
+	failCards bool // fail the card creations
+}
+
+func newTestServer(t *testing.T) *testServer {
+	s := &testServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// client returns a mochi client sending the requests to the server.
+func (s *testServer) client() *mochi.Client {
+	target, _ := url.Parse(s.URL)
+	return mochi.New("TOKEN", mochi.WithTransport(&testTransport{target: target}))
+}
+
+func (s *testServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/decks":
+		writeJSON(w, map[string]any{"docs": s.decks})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/decks":
+		var deck mochi.Deck
+		_ = json.NewDecoder(r.Body).Decode(&deck)
+		deck.ID = fmt.Sprintf("deck-%d", len(s.decks)+1)
+		s.decks = append(s.decks, deck)
+		writeJSON(w, deck)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/cards":
+		deckID := r.URL.Query().Get("deck-id")
+		cards := []mochi.Card{}
+		for _, card := range s.cards {
+			if card.DeckID == deckID {
+				cards = append(cards, card)
+			}
+		}
+		writeJSON(w, map[string]any{"docs": cards})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/cards" && s.failCards:
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	case r.Method == http.MethodPost && r.URL.Path == "/api/cards":
+		var card mochi.Card
+		_ = json.NewDecoder(r.Body).Decode(&card)
+		card.ID = fmt.Sprintf("card-%d", len(s.cards)+1)
+		s.cards = append(s.cards, card)
+		writeJSON(w, card)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// testTransport redirects the requests to the target.
+type testTransport struct {
+	target *url.URL
+}
+
+func (t *testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// testParser returns a card for each line.
+type testParser struct{}
+
+func (testParser) Parse(path string, source []byte) (ParseResult, error) {
+	var cards []Card
+	for i, line := range strings.SplitAfter(strings.TrimSpace(string(source)), "\n") {
+		cards = append(cards, Card{
+			Content:  strings.TrimSpace(line) + "\n",
+			Fields:   map[string]string{"name": strings.TrimSpace(line)},
+			Path:     path,
+			Position: fmt.Sprintf("%04d", i),
+		})
+	}
+	return ParseResult{Deck: "Chess", Cards: cards}, nil
+}
+
+// testExtension removes the headings.
+type testExtension struct{}
+
+func (testExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(testExtension{}, 1000)))
+}
+
+func (testExtension) Transform(node *ast.Document, _ text.Reader, _ parser.Context) {
+	var headings []ast.Node
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if n.Kind() == ast.KindHeading && entering {
+			headings = append(headings, n)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, heading := range headings {
+		heading.Parent().RemoveChild(heading.Parent(), heading)
+	}
+}
+
+type testLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) Debugf(string, ...any) {}
+func (l *testLogger) Errorf(string, ...any) {}
+
+func (l *testLogger) Infof(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}